@@ -0,0 +1,55 @@
+#include <SDL2/SDL_mixer.h>
+
+extern void go_mixer_channel_finished(int channel);
+
+/* Installs or removes the Go channel finished hook. */
+void go_mixer_set_channel_finished(int enable) {
+	Mix_ChannelFinished(enable ? &go_mixer_channel_finished : NULL);
+}
+
+extern void go_mixer_effect(int chan, void* stream, int len);
+extern void go_mixer_effect_done(int chan);
+extern void go_mixer_post_mix(Uint8* stream, int len);
+
+/*
+ * Every channel gets at most one instance of this effect; the Go side
+ * keeps the chain of Go effects for each channel.
+ */
+static void go_mixer_effect_trampoline(int chan, void* stream, int len, void* udata) {
+	go_mixer_effect(chan, stream, len);
+}
+
+static void go_mixer_effect_done_trampoline(int chan, void* udata) {
+	go_mixer_effect_done(chan);
+}
+
+int go_mixer_register_effect(int chan) {
+	return Mix_RegisterEffect(chan, &go_mixer_effect_trampoline, &go_mixer_effect_done_trampoline, NULL);
+}
+
+int go_mixer_unregister_effect(int chan) {
+	return Mix_UnregisterEffect(chan, &go_mixer_effect_trampoline);
+}
+
+static void go_mixer_post_mix_trampoline(void* udata, Uint8* stream, int len) {
+	go_mixer_post_mix(stream, len);
+}
+
+void go_mixer_set_post_mix(int enable) {
+	Mix_SetPostMix(enable ? &go_mixer_post_mix_trampoline : NULL, NULL);
+}
+
+extern void go_mixer_music_finished(void);
+extern void go_mixer_music_hook(Uint8* stream, int len);
+
+void go_mixer_set_music_finished(int enable) {
+	Mix_HookMusicFinished(enable ? &go_mixer_music_finished : NULL);
+}
+
+static void go_mixer_music_hook_trampoline(void* udata, Uint8* stream, int len) {
+	go_mixer_music_hook(stream, len);
+}
+
+void go_mixer_set_music_hook(int enable) {
+	Mix_HookMusic(enable ? &go_mixer_music_hook_trampoline : NULL, NULL);
+}
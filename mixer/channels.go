@@ -0,0 +1,91 @@
+package mixer
+
+// #cgo pkg-config: SDL2_mixer
+// #include <SDL2/SDL_mixer.h>
+//
+// void go_mixer_set_channel_finished(int enable);
+import "C"
+import "sync"
+
+var channelFinished struct {
+	sync.Mutex
+	f func(channel int)
+}
+
+//export go_mixer_channel_finished
+func go_mixer_channel_finished(channel C.int) {
+	channelFinished.Lock()
+	f := channelFinished.f
+	channelFinished.Unlock()
+	if f != nil {
+		f(int(channel))
+	}
+}
+
+// Sets the number of channels being mixed.  Returns the number of
+// channels allocated; passing a negative number just returns the current
+// number.
+func AllocateChannels(numchans int) int {
+	return int(C.Mix_AllocateChannels(C.int(numchans)))
+}
+
+// Sets the volume of a channel, or of all channels if channel is -1.
+// Returns the (average) previous volume; passing a negative volume just
+// returns the current one.
+func Volume(channel, volume int) int {
+	return int(C.Mix_Volume(C.int(channel), C.int(volume)))
+}
+
+// Pauses a channel, or all channels if channel is -1.
+func Pause(channel int) { C.Mix_Pause(C.int(channel)) }
+
+// Unpauses a channel, or all channels if channel is -1.
+func Resume(channel int) { C.Mix_Resume(C.int(channel)) }
+
+// Halts playback on a channel, or all channels if channel is -1.
+func HaltChannel(channel int) int { return int(C.Mix_HaltChannel(C.int(channel))) }
+
+// Halts playback on a channel, or all channels if channel is -1, after
+// the ticks specified.  Passing -1 for ticks removes the expiration.
+// Returns the number of channels set to expire.
+func ExpireChannel(channel, ticks int) int {
+	return int(C.Mix_ExpireChannel(C.int(channel), C.int(ticks)))
+}
+
+// Fades out a channel, or all channels if channel is -1, over the
+// milliseconds specified.  The channel is halted after the fade out is
+// completed.  Returns the number of channels set to fade out.
+func FadeOutChannel(channel, ms int) int {
+	return int(C.Mix_FadeOutChannel(C.int(channel), C.int(ms)))
+}
+
+// Returns 1 if the channel is playing and 0 if not.  If channel is -1,
+// returns the number of channels playing.
+func Playing(channel int) int { return int(C.Mix_Playing(C.int(channel))) }
+
+// Returns 1 if the channel is paused and 0 if not.  If channel is -1,
+// returns the number of channels paused.
+func Paused(channel int) int { return int(C.Mix_Paused(C.int(channel))) }
+
+// Tells you whether a channel is fading in, out, or not at all.
+func FadingChannel(which int) int { return int(C.Mix_FadingChannel(C.int(which))) }
+
+// Sets a function to be called with the channel number whenever a
+// channel finishes playback, or is halted.  Passing nil removes it.
+//
+// The function is called on the audio thread while the mixer is locked,
+// so it must return quickly and must not call into the mixer; hand the
+// channel number off instead, e.g. over a buffered Go channel.
+func ChannelFinished(f func(channel int)) {
+	channelFinished.Lock()
+	channelFinished.f = f
+	channelFinished.Unlock()
+	C.go_mixer_set_channel_finished(C.int(bool2int(f != nil)))
+}
+
+func bool2int(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
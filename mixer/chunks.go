@@ -3,11 +3,16 @@ package mixer
 // #cgo pkg-config: SDL2_mixer
 // #include <SDL2/SDL_mixer.h>
 import "C"
-import "unsafe"
+import (
+	"unsafe"
+
+	"github.com/krig/Go-SDL2/sdl"
+)
 
 // A Chunk file.
 type Chunk struct {
 	cchunk *C.Mix_Chunk
+	mem    unsafe.Pointer // C copy of the samples passed to QuickLoad_RAW
 }
 
 // Loads a sound file to use.
@@ -22,12 +27,71 @@ func LoadWAV(file string) *Chunk {
 	if cchunk == nil {
 		return nil
 	}
-	return &Chunk{cchunk}
+	return &Chunk{cchunk: cchunk}
+}
+
+// Loads a sound from an SDL data stream.  If freesrc is true, src is
+// closed afterwards and must not be used or freed by the caller anymore.
+func LoadWAV_RW(src *sdl.RWops, freesrc bool) *Chunk {
+	cchunk := C.Mix_LoadWAV_RW((*C.SDL_RWops)(src.CRWops()), C.int(bool2int(freesrc)))
+	if freesrc {
+		src.Detach()
+	}
+	if cchunk == nil {
+		return nil
+	}
+	return &Chunk{cchunk: cchunk}
+}
+
+// Loads a sound from memory, e.g. from a file embedded with go:embed.
+func LoadWAV_Mem(data []byte) *Chunk {
+	if len(data) == 0 {
+		return nil
+	}
+	mem := C.CBytes(data)
+	defer C.free(mem)
+	cchunk := C.Mix_LoadWAV_RW(C.SDL_RWFromConstMem(mem, C.int(len(data))), 1)
+	if cchunk == nil {
+		return nil
+	}
+	return &Chunk{cchunk: cchunk}
+}
+
+// Creates a chunk from raw samples, e.g. generated ones, which must
+// already be in the format the mixer was opened with.  The samples are
+// copied.
+func QuickLoad_RAW(samples []byte) *Chunk {
+	if len(samples) == 0 {
+		return nil
+	}
+	mem := C.CBytes(samples)
+	cchunk := C.Mix_QuickLoad_RAW((*C.Uint8)(mem), C.Uint32(len(samples)))
+	if cchunk == nil {
+		C.free(mem)
+		return nil
+	}
+	return &Chunk{cchunk: cchunk, mem: mem}
+}
+
+// Returns the size of the samples in bytes.
+func (c *Chunk) length() int { return int(c.cchunk.alen) }
+
+// Returns a new chunk holding a copy of the first n bytes of the
+// samples, or of all of them if there are fewer.
+func (c *Chunk) head(n int) *Chunk {
+	if n > c.length() {
+		n = c.length()
+	}
+	return QuickLoad_RAW(C.GoBytes(unsafe.Pointer(c.cchunk.abuf), C.int(n)))
 }
 
 // Frees the loaded sound file.
 func (c *Chunk) Free() {
 	C.Mix_FreeChunk(c.cchunk)
+	if c.mem != nil {
+		C.free(c.mem)
+		c.mem = nil
+	}
 }
 
 func (c *Chunk) Volume(volume int) int {
@@ -55,5 +119,14 @@ func GetChunk(channel int) *Chunk {
 	if out == nil {
 		return nil
 	}
-	return &Chunk{out}
+	return &Chunk{cchunk: out}
+}
+
+// Returns the number of chunk decoders available.  Call after
+// OpenAudio, since decoders may be initialized lazily.
+func GetNumChunkDecoders() int { return int(C.Mix_GetNumChunkDecoders()) }
+
+// Returns the name of a chunk decoder, e.g. "WAVE" or "OGG".
+func GetChunkDecoder(index int) string {
+	return C.GoString(C.Mix_GetChunkDecoder(C.int(index)))
 }
@@ -16,6 +16,16 @@ const (
 	MAX_VOLUME        = 128
 )
 
+// Flags for Init
+const (
+	INIT_FLAC = 0x01
+	INIT_MOD  = 0x02
+	INIT_MP3  = 0x08
+	INIT_OGG  = 0x10
+	INIT_MID  = 0x20
+	INIT_OPUS = 0x40
+)
+
 const (
 	MUS_NONE = iota
 	MUS_CMD
@@ -26,6 +36,10 @@ const (
 	MUS_MP3
 )
 
+// Pass to the effect functions to apply an effect to the final mixed
+// stream rather than to a single channel.
+const CHANNEL_POST = -2
+
 const (
 	NO_FADING = iota
 	FADING_OUT
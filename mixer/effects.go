@@ -0,0 +1,274 @@
+package mixer
+
+// #cgo pkg-config: SDL2_mixer
+// #include <SDL2/SDL_mixer.h>
+//
+// int go_mixer_register_effect(int chan);
+// int go_mixer_unregister_effect(int chan);
+// void go_mixer_set_post_mix(int enable);
+import "C"
+import (
+	"math"
+	"sync"
+	"unsafe"
+)
+
+// Sets the panning of a channel, or of the post-mix stream if channel
+// is CHANNEL_POST.  Volumes range from 0 to 255; passing 255 for both
+// removes the effect.  Returns 0 on errors.
+func SetPanning(channel int, left, right uint8) int {
+	return int(C.Mix_SetPanning(C.int(channel), C.Uint8(left), C.Uint8(right)))
+}
+
+// Simulates a sound source at a distance from the listener by
+// attenuating the channel; 0 is right at the listener (and removes the
+// effect) and 255 is as far away as possible.  Returns 0 on errors.
+func SetDistance(channel int, distance uint8) int {
+	return int(C.Mix_SetDistance(C.int(channel), C.Uint8(distance)))
+}
+
+// Simulates a sound source at an angle and distance from the listener.
+// An angle of 0 is straight ahead, 90 to the right, 180 behind and 270
+// to the left; distance is as for SetDistance.  Passing 0 for both
+// removes the effect.  Returns 0 on errors.
+func SetPosition(channel int, angle int16, distance uint8) int {
+	return int(C.Mix_SetPosition(C.int(channel), C.Sint16(angle), C.Uint8(distance)))
+}
+
+// Swaps the left and right speakers of a channel.  Returns 0 on errors.
+func SetReverseStereo(channel int, flip bool) int {
+	return int(C.Mix_SetReverseStereo(C.int(channel), C.int(bool2int(flip))))
+}
+
+// Computes the arguments to SetPosition for a sound emitted at
+// (emitterX, emitterY) and heard at (listenerX, listenerY), in screen
+// coordinates where y grows downwards and the listener faces up.
+// Emitters at maxDistance or further away get the maximum distance of
+// 255.
+func Spatialize(listenerX, listenerY, emitterX, emitterY, maxDistance float64) (int16, uint8) {
+	dx := emitterX - listenerX
+	dy := emitterY - listenerY
+
+	// Clockwise from straight up, in degrees 0..359.
+	angle := int(math.Floor(math.Atan2(dx, -dy)*180/math.Pi+0.5)) % 360
+	if angle < 0 {
+		angle += 360
+	}
+
+	d := math.Hypot(dx, dy) / maxDistance
+	if d > 1 || math.IsNaN(d) {
+		d = 1
+	}
+	return int16(angle), uint8(d*255 + 0.5)
+}
+
+// Positions a channel as described by Spatialize.  Returns 0 on errors.
+func SetSpatialPosition(channel int, listenerX, listenerY, emitterX, emitterY, maxDistance float64) int {
+	angle, distance := Spatialize(listenerX, listenerY, emitterX, emitterY, maxDistance)
+	return SetPosition(channel, angle, distance)
+}
+
+// Returns the actual audio format in use by the opened mixer.
+// Return values are (frequency, format, channels, opened), where opened
+// is the number of times the mixer has been opened, or 0 on errors.
+func QuerySpec() (int, uint16, int, int) {
+	var frequency, channels C.int
+	var format C.Uint16
+	opened := int(C.Mix_QuerySpec(&frequency, &format, &channels))
+	return int(frequency), uint16(format), int(channels), opened
+}
+
+// A block of audio passed to Go effects, in the format the mixer was
+// opened with.  The samples are only valid while the effect runs.
+type Samples struct {
+	Format   uint16 // One of the AUDIO_* formats
+	Channels int    // Number of interleaved output channels
+	Bytes    []byte // The raw samples, modified in place by effects
+}
+
+// Returns the samples as 16-bit integers, or nil unless the mixer
+// format is native-endian signed 16-bit.
+func (s Samples) S16() []int16 {
+	if s.Format != C.AUDIO_S16SYS || len(s.Bytes) < 2 {
+		return nil
+	}
+	n := len(s.Bytes) / 2
+	return (*[1 << 29]int16)(unsafe.Pointer(&s.Bytes[0]))[:n:n]
+}
+
+// Returns the samples as 32-bit floats, or nil unless the mixer format
+// is native-endian 32-bit float.
+func (s Samples) F32() []float32 {
+	if s.Format != C.AUDIO_F32SYS || len(s.Bytes) < 4 {
+		return nil
+	}
+	n := len(s.Bytes) / 4
+	return (*[1 << 28]float32)(unsafe.Pointer(&s.Bytes[0]))[:n:n]
+}
+
+// An effect processes the audio of a channel in place.  Effects run on
+// the audio thread while the mixer is locked, so they must be fast and
+// must not call into the mixer.
+type EffectFunc func(channel int, samples Samples)
+
+// Called when an effect is removed from a channel, either by
+// UnregisterEffect or because the channel finished playing.  In the
+// latter case it runs on the audio thread with the mixer locked, so
+// like an EffectFunc it must be fast and must not call into the mixer,
+// RegisterEffect or UnregisterEffect included.
+type EffectDone func(channel int)
+
+// Identifies a registered effect.
+type EffectID uintptr
+
+type effect struct {
+	id   EffectID
+	f    EffectFunc
+	done EffectDone
+}
+
+// Serializes RegisterEffect and UnregisterEffect, so that a chain and
+// the C effect running it are always added and removed together.  The
+// audio thread never takes this lock.
+var effectsRegistration sync.Mutex
+
+// The Go effects of each channel, run in registration order by one C
+// effect registered per channel.  The mixer is never called with the
+// mutex held, since the audio thread takes it with the mixer locked.
+var effects = struct {
+	sync.Mutex
+	next     EffectID
+	chains   map[int][]effect
+	postMix  func(Samples)
+	format   uint16
+	channels int
+}{chains: make(map[int][]effect)}
+
+func samples(stream unsafe.Pointer, length C.int) Samples {
+	n := int(length)
+	return Samples{
+		Format:   effects.format,
+		Channels: effects.channels,
+		Bytes:    (*[1 << 30]byte)(stream)[:n:n],
+	}
+}
+
+//export go_mixer_effect
+func go_mixer_effect(channel C.int, stream unsafe.Pointer, length C.int) {
+	effects.Lock()
+	chain := effects.chains[int(channel)]
+	s := samples(stream, length)
+	effects.Unlock()
+	for _, e := range chain {
+		e.f(int(channel), s)
+	}
+}
+
+//export go_mixer_effect_done
+func go_mixer_effect_done(channel C.int) {
+	effects.Lock()
+	chain := effects.chains[int(channel)]
+	delete(effects.chains, int(channel))
+	effects.Unlock()
+	for _, e := range chain {
+		if e.done != nil {
+			e.done(int(channel))
+		}
+	}
+}
+
+//export go_mixer_post_mix
+func go_mixer_post_mix(stream *C.Uint8, length C.int) {
+	effects.Lock()
+	f := effects.postMix
+	s := samples(unsafe.Pointer(stream), length)
+	effects.Unlock()
+	if f != nil {
+		f(s)
+	}
+}
+
+// Remembers the mixer format for the Samples passed to Go effects.
+func updateEffectFormat() {
+	_, format, channels, _ := QuerySpec()
+	effects.Lock()
+	effects.format, effects.channels = format, channels
+	effects.Unlock()
+}
+
+// Registers a Go effect on a channel, or on the final mixed stream if
+// channel is CHANNEL_POST.  Effects on a channel run in the order they
+// were registered, and are removed when the channel finishes playing or
+// is halted, calling done (which may be nil).  Returns the ID of the
+// effect and 1, or 0 and 0 on errors.
+func RegisterEffect(channel int, f EffectFunc, done EffectDone) (EffectID, int) {
+	effectsRegistration.Lock()
+	defer effectsRegistration.Unlock()
+	updateEffectFormat()
+
+	effects.Lock()
+	effects.next++
+	e := effect{effects.next, f, done}
+	first := len(effects.chains[channel]) == 0
+	effects.chains[channel] = append(effects.chains[channel], e)
+	effects.Unlock()
+
+	if first && C.go_mixer_register_effect(C.int(channel)) == 0 {
+		effects.Lock()
+		delete(effects.chains, channel)
+		effects.Unlock()
+		return 0, 0
+	}
+	return e.id, 1
+}
+
+// Removes a Go effect from a channel, calling its done function.
+// Returns 1 if the effect was registered on the channel and 0 if not.
+func UnregisterEffect(channel int, id EffectID) int {
+	effectsRegistration.Lock()
+	effects.Lock()
+	chain := effects.chains[channel]
+	var removed effect
+	for i, e := range chain {
+		if e.id == id {
+			// Copy, since the audio thread may be running the old chain.
+			removed = e
+			chain = append(chain[:i:i], chain[i+1:]...)
+			break
+		}
+	}
+	if len(chain) == 0 {
+		delete(effects.chains, channel)
+	} else {
+		effects.chains[channel] = chain
+	}
+	effects.Unlock()
+
+	if removed.id != 0 && len(chain) == 0 {
+		// The chain is already empty, so the C done callback that
+		// this triggers has nothing left to do.
+		C.go_mixer_unregister_effect(C.int(channel))
+	}
+	effectsRegistration.Unlock()
+
+	if removed.id == 0 {
+		return 0
+	}
+	// Outside the locks, as when the channel finishes.  done must still
+	// not call into the mixer; see EffectDone.
+	if removed.done != nil {
+		removed.done(channel)
+	}
+	return 1
+}
+
+// Sets a function to process the final mixed stream after all channel
+// and CHANNEL_POST effects, e.g. for metering.  Passing nil removes it.
+// Like effects, it runs on the audio thread with the mixer locked.
+func SetPostMix(f func(samples Samples)) {
+	updateEffectFormat()
+	effects.Lock()
+	effects.postMix = f
+	effects.Unlock()
+	C.go_mixer_set_post_mix(C.int(bool2int(f != nil)))
+}
@@ -0,0 +1,68 @@
+package mixer
+
+// #cgo pkg-config: SDL2_mixer
+// #include <SDL2/SDL_mixer.h>
+import "C"
+
+// A group of channels, identified by a tag, so that different kinds of
+// sounds (UI, footsteps, dialogue...) can each be given a budget of
+// channels.
+type ChannelGroup int
+
+// The group all channels are in by default.
+const DefaultGroup ChannelGroup = -1
+
+// Reserves the first num channels from being used when playing samples
+// on channel -1, so they can be grouped or used directly.  Returns the
+// number of channels reserved.
+func ReserveChannels(num int) int { return int(C.Mix_ReserveChannels(C.int(num))) }
+
+// Adds a channel to a group, or removes it from its group when tag is
+// DefaultGroup.  Returns 1 if successful and 0 if not.
+func GroupChannel(which int, tag ChannelGroup) int {
+	return int(C.Mix_GroupChannel(C.int(which), C.int(tag)))
+}
+
+// Adds the channels from through to, inclusive, to a group.  Returns
+// the number of channels grouped.
+func GroupChannels(from, to int, tag ChannelGroup) int {
+	return int(C.Mix_GroupChannels(C.int(from), C.int(to), C.int(tag)))
+}
+
+// Returns the first channel in the group that is not playing, or -1 if
+// all of them are.
+func (g ChannelGroup) Available() int { return int(C.Mix_GroupAvailable(C.int(g))) }
+
+// Returns the number of channels in the group.
+func (g ChannelGroup) Count() int { return int(C.Mix_GroupCount(C.int(g))) }
+
+// Returns the channel in the group that has been playing the longest,
+// or -1 if none are playing.
+func (g ChannelGroup) Oldest() int { return int(C.Mix_GroupOldest(C.int(g))) }
+
+// Returns the channel in the group that started playing most recently,
+// or -1 if none are playing.
+func (g ChannelGroup) Newer() int { return int(C.Mix_GroupNewer(C.int(g))) }
+
+// Fades out all channels in the group over the milliseconds specified.
+// Returns the number of channels set to fade out.
+func (g ChannelGroup) FadeOut(ms int) int { return int(C.Mix_FadeOutGroup(C.int(g), C.int(ms))) }
+
+// Halts playback on all channels in the group.
+func (g ChannelGroup) Halt() int { return int(C.Mix_HaltGroup(C.int(g))) }
+
+// Plays a chunk on a free channel of the group, looping a specified
+// number of times.  If every channel of the group is busy, the one that
+// has been playing the longest is halted and reused.  Returns the
+// channel played on, or -1 on errors.
+func (g ChannelGroup) Play(c *Chunk, loops int) int {
+	channel := g.Available()
+	if channel == -1 {
+		channel = g.Oldest()
+		if channel == -1 {
+			return -1
+		}
+		HaltChannel(channel)
+	}
+	return c.PlayChannel(channel, loops)
+}
@@ -0,0 +1,67 @@
+package mixer
+
+// #cgo pkg-config: SDL2_mixer
+// #include <SDL2/SDL_mixer.h>
+//
+// void go_mixer_set_music_finished(int enable);
+// void go_mixer_set_music_hook(int enable);
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+var musicHooks struct {
+	sync.Mutex
+	finished func()
+	hook     func(stream []byte)
+}
+
+//export go_mixer_music_finished
+func go_mixer_music_finished() {
+	musicHooks.Lock()
+	f := musicHooks.finished
+	musicHooks.Unlock()
+	if f != nil {
+		f()
+	}
+}
+
+//export go_mixer_music_hook
+func go_mixer_music_hook(stream *C.Uint8, length C.int) {
+	musicHooks.Lock()
+	f := musicHooks.hook
+	musicHooks.Unlock()
+	if f != nil {
+		n := int(length)
+		f((*[1 << 30]byte)(unsafe.Pointer(stream))[:n:n])
+	}
+}
+
+// Sets a function to be called when music stops playing, either because
+// it ended or because it was halted.  Passing nil removes it.
+//
+// The function is called on the audio thread while the mixer is locked,
+// so it must return quickly and must not call into the mixer; signal
+// another goroutine to start the next song instead.
+//
+// NewPlaylist replaces this hook with its own, and Playlist.Close
+// removes it.
+func HookMusicFinished(f func()) {
+	musicHooks.Lock()
+	musicHooks.finished = f
+	musicHooks.Unlock()
+	C.go_mixer_set_music_finished(C.int(bool2int(f != nil)))
+}
+
+// Replaces music playback with a function that fills the music stream
+// itself, in the format the mixer was opened with (see QuerySpec), so
+// Go-generated music can be mixed with the channels.  Passing nil
+// removes it.  Like the hook of HookMusicFinished, the function runs on
+// the audio thread with the mixer locked.
+func HookMusic(f func(stream []byte)) {
+	musicHooks.Lock()
+	musicHooks.hook = f
+	musicHooks.Unlock()
+	C.go_mixer_set_music_hook(C.int(bool2int(f != nil)))
+}
@@ -10,13 +10,29 @@ package mixer
 // #cgo pkg-config: SDL2_mixer
 // #include <SDL2/SDL_mixer.h>
 import "C"
-import "unsafe"
+import (
+	"unsafe"
+
+	"github.com/krig/Go-SDL2/sdl"
+)
 
 // A music file.
 type Music struct {
 	cmusic *C.Mix_Music
+	src    *sdl.RWops     // Data stream the music is decoded from while playing
+	file   string         // File name passed to LoadMUS
+	mem    unsafe.Pointer // C copy of the data passed to LoadMUS_Mem
+	memLen int
 }
 
+// Loads dynamic libraries for the MIX_INIT_* decoders in flags.  Returns
+// the flags of all decoders that are now initialized; compare it to
+// flags to find out which failed.
+func Init(flags int) int { return int(C.Mix_Init(C.int(flags))) }
+
+// Unloads the libraries loaded by Init.
+func Quit() { C.Mix_Quit() }
+
 // Initializes SDL_mixer.  Return 0 if successful and -1 if there were
 // initialization errors.
 func OpenAudio(frequency int, format uint16, channels, chunksize int) int {
@@ -37,11 +53,66 @@ func LoadMUS(file string) *Music {
 		return nil
 	}
 
-	return &Music{cmusic}
+	return &Music{cmusic: cmusic, file: file}
+}
+
+// Loads music from an SDL data stream.  Music is decoded while it plays,
+// so src must stay open until the music is freed; if freesrc is true,
+// src is closed by Free (or right away on errors) and must not be used
+// or freed by the caller anymore.
+func LoadMUS_RW(src *sdl.RWops, freesrc bool) *Music {
+	cmusic := C.Mix_LoadMUS_RW((*C.SDL_RWops)(src.CRWops()), C.int(bool2int(freesrc)))
+	if freesrc {
+		src.Detach()
+	}
+	if cmusic == nil {
+		return nil
+	}
+	return &Music{cmusic: cmusic, src: src}
+}
+
+// Loads music from memory, e.g. from a file embedded with go:embed.  The
+// data is copied.
+func LoadMUS_Mem(data []byte) *Music {
+	if len(data) == 0 {
+		return nil
+	}
+	mem := C.CBytes(data)
+	cmusic := C.Mix_LoadMUS_RW(C.SDL_RWFromConstMem(mem, C.int(len(data))), 1)
+	if cmusic == nil {
+		C.free(mem)
+		return nil
+	}
+	return &Music{cmusic: cmusic, mem: mem, memLen: len(data)}
+}
+
+// Decodes the whole music into a chunk in the mixer format, so that it
+// can be played on a channel.  Only music loaded with LoadMUS or
+// LoadMUS_Mem can be read a second time; returns nil for other music
+// and on errors.
+func (m *Music) decode() *Chunk {
+	switch {
+	case m.file != "":
+		return LoadWAV(m.file)
+	case m.mem != nil:
+		cchunk := C.Mix_LoadWAV_RW(C.SDL_RWFromConstMem(m.mem, C.int(m.memLen)), 1)
+		if cchunk == nil {
+			return nil
+		}
+		return &Chunk{cchunk: cchunk}
+	}
+	return nil
 }
 
 // Frees the loaded music file.
-func (m *Music) Free() { C.Mix_FreeMusic(m.cmusic) }
+func (m *Music) Free() {
+	C.Mix_FreeMusic(m.cmusic)
+	if m.mem != nil {
+		C.free(m.mem)
+		m.mem = nil
+	}
+	m.src = nil
+}
 
 // Play the music and loop a specified number of times.  Passing -1 makes
 // the music loop continuously.
@@ -100,3 +171,12 @@ func PausedMusic() int { return int(C.Mix_PausedMusic()) }
 
 // Tells you whether music is fading in, out, or not at all.
 func FadingMusic() int { return int(C.Mix_FadingMusic()) }
+
+// Returns the number of music decoders available.  Call after
+// OpenAudio, since decoders may be initialized lazily.
+func GetNumMusicDecoders() int { return int(C.Mix_GetNumMusicDecoders()) }
+
+// Returns the name of a music decoder, e.g. "OGG" or "MP3".
+func GetMusicDecoder(index int) string {
+	return C.GoString(C.Mix_GetMusicDecoder(C.int(index)))
+}
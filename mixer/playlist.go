@@ -0,0 +1,863 @@
+package mixer
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/krig/Go-SDL2/sdl"
+)
+
+// A Curve maps the progress of a fade, from 0 to 1, to a volume factor
+// from 0 (silent) to 1 (full volume).  It is used reversed for fading
+// out.
+type Curve func(t float64) float64
+
+var (
+	// Changes the volume at a constant rate.
+	LinearCurve Curve = func(t float64) float64 { return t }
+
+	// Keeps the perceived loudness even over a fade.
+	EqualPowerCurve Curve = func(t float64) float64 { return math.Sin(t * math.Pi / 2) }
+
+	// Starts slowly and ends quickly, which sounds natural since
+	// loudness is perceived logarithmically.
+	CubicCurve Curve = func(t float64) float64 { return t * t * t }
+)
+
+// What a Playlist does after a track ends.
+type RepeatMode int
+
+const (
+	REPEAT_NONE RepeatMode = iota // Stop after the last track
+	REPEAT_ALL                    // Start over after the last track
+	REPEAT_ONE                    // Play the same track again
+)
+
+// How often fades update the music volume.
+const fadeStep = 10 * time.Millisecond
+
+// How much longer than a crossfade the decoded start of a track is, so
+// that it does not run out before it is handed over to the music
+// stream.
+const introSlack = time.Second
+
+// How long handing over waits for the crossfade channel to be mixed.
+const handOverTimeout = 100 * time.Millisecond
+
+// Returned by Playlist methods that need a current track.
+var ErrNotPlaying = errors.New("mixer: playlist is not playing")
+
+// A fade of the music stream, when not crossfading.
+type fade struct {
+	start time.Time
+	in    bool // Fading in rather than out
+	next  int  // After fading out: the position to play next, or -1 to stop
+}
+
+// A crossfade in progress: the outgoing track fades out on the music
+// stream while the incoming one fades in on the crossfade channel,
+// played from its decoded start.
+type crossfade struct {
+	mixed int64 // Accessed atomically; bytes of the channel mixed so far
+
+	start  time.Time
+	from   int           // Music volume the outgoing track fades out from
+	effect EffectID      // Counts the mixed bytes
+	mixes  chan struct{} // Signalled after each mix of the channel
+}
+
+// A track and what the playlist knows about it for crossfades.
+type track struct {
+	music    *Music
+	intro    *Chunk        // The decoded start of the track, or nil
+	duration time.Duration // Length of the track, or 0 if unknown
+	loading  bool
+	loaded   bool // Decoding has been tried
+}
+
+// A Playlist plays a queue of music tracks one after another, with
+// crossfades between them, shuffling and repeating.
+//
+// SDL_mixer plays a single music stream at a time, so during a
+// crossfade the incoming track plays on a mixer channel, from its start
+// decoded into a chunk, and moves to the music stream once the outgoing
+// track has faded out.  See SetCrossfade.  Tracks are decoded in the
+// background when they are about to be played, which needs them to have
+// been loaded with LoadMUS or LoadMUS_Mem; with other tracks, or before
+// decoding is done, the current track fades out and the next one fades
+// in afterwards instead.
+//
+// A Playlist uses HookMusicFinished to notice tracks ending, replacing
+// any hook set before, so only one may exist at a time and the hook must
+// not be changed until it is closed.
+// All methods are safe to call from multiple goroutines.
+type Playlist struct {
+	mu        sync.Mutex
+	tracks    []*track
+	order     []int // Indices into tracks in play order
+	pos       int   // Position of the current track in order, -1 when stopped
+	shuffle   bool
+	repeat    RepeatMode
+	fadeTime  time.Duration
+	curve     Curve
+	channel   int // Channel for incoming tracks of crossfades, or -1
+	volume    int
+	fade      *fade
+	xfade     *crossfade
+	playBase  time.Duration // Position in the current track at playStart
+	playStart time.Time
+	autoFaded bool // Crossfading at the end of the current track was tried
+	paused    bool
+	pausedAt  time.Time
+	loads     int // Bumped whenever decoded tracks are discarded
+	loaders   sync.WaitGroup
+	rand      *rand.Rand
+
+	closeOnce sync.Once
+	finished  chan struct{}
+	wake      chan struct{}
+	quit      chan struct{}
+	done      chan struct{}
+}
+
+// Creates an empty playlist and installs its music finished hook,
+// replacing the one set with HookMusicFinished, if any.
+func NewPlaylist() *Playlist {
+	p := &Playlist{
+		pos:      -1,
+		curve:    EqualPowerCurve,
+		channel:  -1,
+		volume:   MAX_VOLUME,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		finished: make(chan struct{}, 1),
+		wake:     make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	HookMusicFinished(func() {
+		// Runs on the audio thread: notify, nothing else.
+		signal(p.finished)
+	})
+	go p.run()
+	return p
+}
+
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// Handles finished tracks, steps fades and starts crossfades at the end
+// of tracks.
+func (p *Playlist) run() {
+	defer close(p.done)
+	for {
+		p.mu.Lock()
+		wait := p.untilStep()
+		p.mu.Unlock()
+
+		var tick <-chan time.Time
+		if wait >= 0 {
+			tick = time.After(wait)
+		}
+		select {
+		case <-p.finished:
+			p.musicFinished()
+		case <-tick:
+			p.mu.Lock()
+			p.step()
+			p.mu.Unlock()
+		case <-p.wake:
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// How long until step has something to do, or -1 if nothing is due.
+// Must be called with mu held.
+func (p *Playlist) untilStep() time.Duration {
+	if p.paused {
+		return -1
+	}
+	if p.fade != nil || p.xfade != nil {
+		return fadeStep
+	}
+	return p.untilCrossfade()
+}
+
+// How long until the crossfade into the next track should start, for
+// it to be over when the current one ends, or -1 if none is due.  Must
+// be called with mu held.
+func (p *Playlist) untilCrossfade() time.Duration {
+	if p.pos < 0 || p.autoFaded || p.channel < 0 || p.fadeTime <= 0 {
+		return -1
+	}
+	duration := p.tracks[p.order[p.pos]].duration
+	next := p.peekNext()
+	if duration < 2*p.fadeTime || next < 0 || p.tracks[p.order[next]].intro == nil {
+		return -1
+	}
+	if wait := duration - p.fadeTime - p.position(); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func (p *Playlist) step() {
+	if p.fade != nil || p.xfade != nil {
+		p.stepFade()
+		return
+	}
+	if p.untilCrossfade() == 0 {
+		p.autoFaded = true
+		if next := p.nextPos(); next >= 0 {
+			p.crossfadeTo(next)
+		}
+	}
+}
+
+// Advances to the next track if the current one ended by itself.  The
+// hook also fires for our own HaltMusic calls, and its notifications
+// are handled late and coalesced, so rather than matching them up with
+// halts, look at whether music is still playing: after a halt we have
+// either started the next track or stopped.  During a crossfade the
+// outgoing track may end early; the crossfade goes on regardless.
+func (p *Playlist) musicFinished() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos < 0 || p.xfade != nil || PlayingMusic() != 0 {
+		return
+	}
+	// The track ended by itself; no need to fade it out.
+	var next int
+	if p.fade != nil && !p.fade.in {
+		// It ended while fading out; go where the fade was headed.
+		next = p.fade.next
+	} else {
+		next = p.nextPos()
+	}
+	p.fade = nil
+	if next >= 0 {
+		p.start(next)
+	} else {
+		p.pos = -1
+		VolumeMusic(p.volume)
+	}
+}
+
+// Returns how far a fade or crossfade that started at start has got,
+// from 0 to 1.
+func (p *Playlist) progress(start time.Time) float64 {
+	if p.fadeTime <= 0 {
+		return 1
+	}
+	return math.Max(0, math.Min(float64(p.now().Sub(start))/float64(p.fadeTime), 1))
+}
+
+func (p *Playlist) stepFade() {
+	if x := p.xfade; x != nil {
+		t := p.progress(x.start)
+		VolumeMusic(int(float64(x.from) * p.curve(1-t)))
+		Volume(p.channel, int(float64(p.volume)*p.curve(t)))
+		if t >= 1 {
+			p.handOver(true)
+		}
+		return
+	}
+	f := p.fade
+	if f == nil {
+		return
+	}
+	t := p.progress(f.start)
+	if f.in {
+		VolumeMusic(int(float64(p.volume) * p.curve(t)))
+		if t >= 1 {
+			p.fade = nil
+		}
+		return
+	}
+	VolumeMusic(int(float64(p.volume) * p.curve(1-t)))
+	if t >= 1 {
+		p.fade = nil
+		p.halt()
+		if f.next >= 0 {
+			p.start(f.next)
+		} else {
+			p.pos = -1
+			VolumeMusic(p.volume)
+		}
+	}
+}
+
+// Halts the music.  musicFinished ignores the resulting hook call, as
+// the caller starts the next track or stops before releasing mu.
+func (p *Playlist) halt() {
+	if PlayingMusic() != 0 {
+		HaltMusic()
+	}
+}
+
+// Returns the time playback has got to: the current time, or the time
+// it was paused at.  Fades and track positions are measured with it,
+// and Resume moves them forward by the time spent paused.
+func (p *Playlist) now() time.Time {
+	if p.paused {
+		return p.pausedAt
+	}
+	return time.Now()
+}
+
+// Returns how far playback of the current track has got, assuming it
+// runs in real time since it started or was sought.
+func (p *Playlist) position() time.Duration {
+	return p.playBase + p.now().Sub(p.playStart)
+}
+
+// Records that the current track is at position d now.
+func (p *Playlist) setPosition(d time.Duration) {
+	p.playBase = d
+	p.playStart = p.now()
+}
+
+// Starts playing the track at position pos of the play order, fading it
+// in if fades are enabled.  Must be called with mu held.
+func (p *Playlist) start(pos int) error {
+	p.halt()
+	p.pos = pos
+	p.fade = nil
+	p.setPosition(0)
+	p.autoFaded = false
+	if p.fadeTime > 0 {
+		VolumeMusic(0)
+		p.fade = &fade{start: p.now(), in: true}
+		signal(p.wake)
+	} else {
+		VolumeMusic(p.volume)
+	}
+	if p.tracks[p.order[pos]].music.PlayMusic(1) != 0 {
+		p.pos = -1
+		p.fade = nil
+		return sdl.NewSDLError()
+	}
+	if p.paused {
+		// Playing unpauses the music.
+		PauseMusic()
+	}
+	p.loadAround()
+	return nil
+}
+
+// Starts crossfading into the track at position pos of the play order.
+// Returns false, changing nothing, if that is not possible: crossfades
+// are disabled, the track has not been decoded, nothing is playing to
+// fade out from, playback is paused or the channel can not be used.
+// Must be called with mu held.
+func (p *Playlist) crossfadeTo(pos int) bool {
+	t := p.tracks[p.order[pos]]
+	if p.channel < 0 || p.fadeTime <= 0 || t.intro == nil || PlayingMusic() == 0 || p.paused {
+		return false
+	}
+	x := &crossfade{
+		start: p.now(),
+		from:  VolumeMusic(-1),
+		mixes: make(chan struct{}, 1),
+	}
+	var ok int
+	x.effect, ok = RegisterEffect(p.channel, func(channel int, samples Samples) {
+		atomic.AddInt64(&x.mixed, int64(len(samples.Bytes)))
+		signal(x.mixes)
+	}, nil)
+	if ok == 0 {
+		return false
+	}
+	Volume(p.channel, 0)
+	if t.intro.PlayChannel(p.channel, 0) < 0 {
+		UnregisterEffect(p.channel, x.effect)
+		return false
+	}
+	p.xfade = x
+	p.fade = nil
+	p.pos = pos
+	p.setPosition(0)
+	p.autoFaded = false
+	p.loadAround()
+	signal(p.wake)
+	return true
+}
+
+// Moves the incoming track of the crossfade from the channel to the
+// music stream, continuing from where the channel has got to.  Waiting
+// for the channel to be mixed first leaves a whole mixing period for
+// the switch, so that it normally falls between two mixes and can not
+// be heard.  A crossfade cut short is finished as a fade in of the
+// music.  Must be called with mu held.
+func (p *Playlist) handOver(wait bool) {
+	x := p.xfade
+	p.xfade = nil
+	if wait {
+		select {
+		case <-x.mixes:
+		default:
+		}
+		select {
+		case <-x.mixes:
+		case <-time.After(handOverTimeout):
+		}
+	}
+	t := p.progress(x.start)
+	bytesPerSecond, _ := mixFormat()
+	var position time.Duration
+	if bytesPerSecond > 0 {
+		position = time.Duration(atomic.LoadInt64(&x.mixed)) * time.Second / time.Duration(bytesPerSecond)
+	}
+
+	p.halt()
+	failed := p.tracks[p.order[p.pos]].music.PlayMusic(1) != 0
+	if !failed {
+		SetMusicPosition(position.Seconds())
+		VolumeMusic(int(float64(p.volume) * p.curve(t)))
+		if p.paused {
+			PauseMusic()
+		}
+	}
+	// Halting the channel also removes the counting effect.
+	HaltChannel(p.channel)
+	if failed {
+		p.pos = -1
+		VolumeMusic(p.volume)
+		return
+	}
+	p.setPosition(position)
+	if t < 1 {
+		p.fade = &fade{start: p.now().Add(-time.Duration(t * float64(p.fadeTime))), in: true}
+		signal(p.wake)
+	}
+}
+
+// Ends a crossfade in progress right away.  Must be called with mu
+// held.
+func (p *Playlist) finishCrossfade() {
+	if p.xfade != nil {
+		p.handOver(false)
+	}
+}
+
+// Moves to position pos of the play order, crossfading or fading out
+// the current track first if fades are enabled.  A negative pos stops
+// playback.  Must be called with mu held.
+func (p *Playlist) moveTo(pos int) error {
+	p.finishCrossfade()
+	if p.fade != nil && !p.fade.in {
+		// Already fading out; just change where to go afterwards.
+		p.fade.next = pos
+		return nil
+	}
+	if pos >= 0 && p.pos >= 0 && p.crossfadeTo(pos) {
+		return nil
+	}
+	if p.fadeTime > 0 && p.pos >= 0 && PlayingMusic() != 0 && !p.paused {
+		// Fade out from wherever a fade in got to.  While paused there
+		// is nothing to hear, so tracks change right away instead.
+		start := p.now()
+		if p.fade != nil && p.fade.in {
+			elapsed := start.Sub(p.fade.start)
+			if elapsed < p.fadeTime {
+				start = start.Add(elapsed - p.fadeTime)
+			}
+		}
+		p.fade = &fade{start: start, next: pos}
+		signal(p.wake)
+		return nil
+	}
+	if pos < 0 {
+		p.fade = nil
+		p.halt()
+		p.pos = -1
+		p.paused = false
+		VolumeMusic(p.volume)
+		return nil
+	}
+	return p.start(pos)
+}
+
+// The position to play after the current one ends, or -1, without
+// reshuffling the play order.
+func (p *Playlist) peekNext() int {
+	switch {
+	case len(p.order) == 0:
+		return -1
+	case p.repeat == REPEAT_ONE && p.pos >= 0:
+		return p.pos
+	case p.pos+1 < len(p.order):
+		return p.pos + 1
+	case p.repeat == REPEAT_ALL:
+		return 0
+	}
+	return -1
+}
+
+// The position to play after the current one ends, or -1.  Reshuffles
+// the play order when starting over.
+func (p *Playlist) nextPos() int {
+	next := p.peekNext()
+	if next == 0 && p.shuffle && p.repeat == REPEAT_ALL && p.pos+1 >= len(p.order) {
+		p.order = p.rand.Perm(len(p.tracks))
+	}
+	return next
+}
+
+// Returns the number of bytes per second and per sample frame of the
+// mixer output, or zeros if the mixer is not open.
+func mixFormat() (int, int) {
+	freq, format, channels, _ := QuerySpec()
+	frame := channels * int(format&0xFF) / 8
+	return freq * frame, frame
+}
+
+// Decodes the current and the next track in the background, if
+// crossfades are enabled, to learn how long the current one is and to
+// have the start of the next one ready.  Must be called with mu held.
+func (p *Playlist) loadAround() {
+	if p.pos >= 0 {
+		p.load(p.tracks[p.order[p.pos]])
+	}
+	if next := p.peekNext(); next >= 0 {
+		p.load(p.tracks[p.order[next]])
+	}
+}
+
+// Decodes a track in the background, keeping its start.  Must be called
+// with mu held.
+func (p *Playlist) load(t *track) {
+	if p.channel < 0 || p.fadeTime <= 0 || t.loading || t.loaded {
+		return
+	}
+	t.loading = true
+	loads := p.loads
+	introTime := p.fadeTime + introSlack
+	p.loaders.Add(1)
+	go func() {
+		defer p.loaders.Done()
+		var intro *Chunk
+		var duration time.Duration
+		if c := t.music.decode(); c != nil {
+			bytesPerSecond, frame := mixFormat()
+			if bytesPerSecond > 0 {
+				duration = time.Duration(c.length()) * time.Second / time.Duration(bytesPerSecond)
+				frames := int(introTime.Seconds() * float64(bytesPerSecond/frame))
+				intro = c.head(frames * frame)
+			}
+			c.Free()
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if loads != p.loads {
+			// Discarded while decoding.
+			if intro != nil {
+				intro.Free()
+			}
+			return
+		}
+		t.loading, t.loaded = false, true
+		t.intro, t.duration = intro, duration
+		signal(p.wake)
+	}()
+}
+
+// Frees the decoded starts of all tracks.  Decoding still in progress
+// is discarded when it finishes.  Must be called with mu held, with no
+// crossfade in progress.
+func (p *Playlist) discardLoads() {
+	p.loads++
+	for _, t := range p.tracks {
+		if t.intro != nil {
+			t.intro.Free()
+			t.intro = nil
+		}
+		t.loading, t.loaded = false, false
+	}
+}
+
+// Adds tracks to the end of the playlist.  When shuffling, they are
+// inserted at random positions among the tracks still to be played.
+// The tracks must not be freed before the playlist is closed.
+func (p *Playlist) Add(tracks ...*Music) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, m := range tracks {
+		p.tracks = append(p.tracks, &track{music: m})
+		i := len(p.order)
+		if p.shuffle {
+			i = p.pos + 1 + p.rand.Intn(len(p.order)-p.pos)
+		}
+		p.order = append(p.order, 0)
+		copy(p.order[i+1:], p.order[i:])
+		p.order[i] = len(p.tracks) - 1
+	}
+	p.loadAround()
+}
+
+// Returns the number of tracks in the playlist.
+func (p *Playlist) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.tracks)
+}
+
+// Returns the index, in the order the tracks were added, of the track
+// playing, or -1 if the playlist is stopped.  During a crossfade, this
+// is the incoming track.
+func (p *Playlist) Current() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos < 0 {
+		return -1
+	}
+	return p.order[p.pos]
+}
+
+// Starts playing from the beginning of the play order, unless already
+// playing.
+func (p *Playlist) Play() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos >= 0 {
+		return nil
+	}
+	if len(p.order) == 0 {
+		return errors.New("mixer: playlist is empty")
+	}
+	p.paused = false
+	return p.start(0)
+}
+
+// Plays the track with the given index, in the order the tracks were
+// added, crossfading from the current one.
+func (p *Playlist) PlayTrack(index int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if index < 0 || index >= len(p.tracks) {
+		return errors.New("mixer: playlist track index out of range")
+	}
+	for pos, i := range p.order {
+		if i == index {
+			return p.moveTo(pos)
+		}
+	}
+	return nil
+}
+
+// Crossfades to the next track, following the repeat mode.  Stops if
+// there is none.
+func (p *Playlist) Next() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos < 0 {
+		return ErrNotPlaying
+	}
+	next := p.pos + 1
+	if next >= len(p.order) {
+		next = -1
+		if p.repeat == REPEAT_ALL {
+			next = 0
+		}
+	}
+	return p.moveTo(next)
+}
+
+// Crossfades to the previous track, following the repeat mode.
+// Restarts the first track if there is none.
+func (p *Playlist) Prev() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos < 0 {
+		return ErrNotPlaying
+	}
+	prev := p.pos - 1
+	if prev < 0 {
+		prev = 0
+		if p.repeat == REPEAT_ALL {
+			prev = len(p.order) - 1
+		}
+	}
+	return p.moveTo(prev)
+}
+
+// Fades out and stops playback.
+func (p *Playlist) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos >= 0 {
+		p.moveTo(-1)
+	}
+}
+
+// Pauses the current track.  Fades and crossfades are paused along
+// with it, and tracks changed to while paused start paused.
+func (p *Playlist) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos < 0 || p.paused {
+		return
+	}
+	p.paused = true
+	p.pausedAt = time.Now()
+	PauseMusic()
+	if p.xfade != nil {
+		Pause(p.channel)
+	}
+}
+
+// Resumes the current track and any fade that was in progress.
+func (p *Playlist) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		return
+	}
+	p.paused = false
+	d := time.Since(p.pausedAt)
+	p.playStart = p.playStart.Add(d)
+	if p.fade != nil {
+		p.fade.start = p.fade.start.Add(d)
+	}
+	if p.xfade != nil {
+		p.xfade.start = p.xfade.start.Add(d)
+		Resume(p.channel)
+	}
+	ResumeMusic()
+	signal(p.wake)
+}
+
+// Jumps to a position in the current track, in seconds for most
+// formats (see SetMusicPosition).  A crossfade in progress is finished
+// first.
+func (p *Playlist) Seek(position float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos < 0 {
+		return ErrNotPlaying
+	}
+	p.finishCrossfade()
+	if SetMusicPosition(position) != 0 {
+		return sdl.NewSDLError()
+	}
+	p.setPosition(time.Duration(position * float64(time.Second)))
+	p.autoFaded = false
+	signal(p.wake)
+	return nil
+}
+
+// Turns shuffling on or off.  Turning it on shuffles the play order
+// with the current track first.
+func (p *Playlist) SetShuffle(shuffle bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current := -1
+	if p.pos >= 0 {
+		current = p.order[p.pos]
+	}
+	p.shuffle = shuffle
+	if shuffle {
+		p.order = p.rand.Perm(len(p.tracks))
+	} else {
+		for i := range p.order {
+			p.order[i] = i
+		}
+	}
+	for pos, i := range p.order {
+		if i == current {
+			if shuffle {
+				p.order[0], p.order[pos] = p.order[pos], p.order[0]
+				pos = 0
+			}
+			p.pos = pos
+		}
+	}
+	p.loadAround()
+}
+
+// Sets what happens when a track ends.
+func (p *Playlist) SetRepeat(mode RepeatMode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.repeat = mode
+	p.loadAround()
+	signal(p.wake)
+}
+
+// Sets the duration of crossfades, the curve the volumes follow and the
+// mixer channel incoming tracks play on until the outgoing track has
+// faded out.  The channel should be kept free for the playlist, e.g.
+// with ReserveChannels; effects registered on it are removed after
+// each crossfade.
+//
+// Passing -1 for the channel makes the current track fade out and the
+// next one fade in afterwards, without overlap, as happens for tracks
+// that can not be decoded.  A duration of 0 disables fades, switching
+// tracks immediately; a nil curve keeps the current one
+// (EqualPowerCurve by default).
+//
+// Each track about to be played is decoded once in the background to
+// find its length, so that crossfades into the next track end with it,
+// and its start is kept in memory.  Moving the incoming track to the
+// music stream relies on SetMusicPosition, which not every music format
+// supports.
+func (p *Playlist) SetCrossfade(d time.Duration, curve Curve, channel int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finishCrossfade()
+	if d != p.fadeTime || channel != p.channel {
+		p.discardLoads()
+	}
+	p.fadeTime = d
+	p.channel = channel
+	if curve != nil {
+		p.curve = curve
+	}
+	p.loadAround()
+	signal(p.wake)
+}
+
+// Sets the volume, from 0 to MAX_VOLUME, that tracks are played and
+// faded in to.
+func (p *Playlist) SetVolume(volume int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.volume = volume
+	if p.fade == nil && p.xfade == nil {
+		VolumeMusic(volume)
+	}
+}
+
+// Halts playback, stops handling finished tracks and removes the music
+// finished hook.  The tracks are not freed, but the decoded starts of
+// them are.  Calling Close again does nothing.
+func (p *Playlist) Close() {
+	p.closeOnce.Do(func() {
+		HookMusicFinished(nil)
+		close(p.quit)
+		<-p.done
+		p.mu.Lock()
+		if p.xfade != nil {
+			p.xfade = nil
+			HaltChannel(p.channel)
+		}
+		p.fade = nil
+		HaltMusic()
+		VolumeMusic(p.volume)
+		p.pos = -1
+		p.discardLoads()
+		p.mu.Unlock()
+		// Decoding reads the tracks, which may be freed after Close.
+		p.loaders.Wait()
+	})
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/krig/Go-SDL2/sdl"
+	"github.com/krig/Go-SDL2/sdl/dispatch"
+	"time"
 )
 
 func loadImage(name string) *sdl.Surface {
@@ -17,35 +20,47 @@ func loadImage(name string) *sdl.Surface {
 }
 
 func main() {
-	if sdl.Init(sdl.INIT_VIDEO) != 0 {
-		panic(sdl.GetError())
-	}
+	err := dispatch.Run(context.Background(), sdl.INIT_VIDEO, func(ctx context.Context, d *dispatch.Dispatcher) {
+		var window *sdl.Window
+		var rend *sdl.Renderer
 
-	defer sdl.Quit()
+		d.Do(func() {
+			window, rend = sdl.CreateWindowAndRenderer(640, 480, sdl.WINDOW_SHOWN|
+				sdl.RENDERER_ACCELERATED|
+				sdl.RENDERER_PRESENTVSYNC)
 
-	window, rend := sdl.CreateWindowAndRenderer(640, 480, sdl.WINDOW_SHOWN |
-		sdl.RENDERER_ACCELERATED |
-		sdl.RENDERER_PRESENTVSYNC)
+			if (window == nil) || (rend == nil) {
+				fmt.Printf("%#v\n", sdl.GetError())
+			}
 
-	if (window == nil) || (rend == nil) {
-		fmt.Printf("%#v\n", sdl.GetError())
-	}
+			window.SetTitle("Podcast Studio")
+		})
 
-	window.SetTitle("Podcast Studio")
+		ticker := time.NewTicker(time.Second / 60)
+		defer ticker.Stop()
 
-	running := true
-	event := &sdl.Event{}
-	for running {
-		for event.Poll() {
-			switch event.Type {
-			case sdl.QUIT:
-				running = false
+		for {
+			select {
+			case event := <-d.Events():
+				switch event.(type) {
+				case sdl.QuitEvent:
+					return
+				}
+			case <-ticker.C:
+				d.Do(func() {
+					rend.SetDrawColor(sdl.Color{R: 0x30, G: 0xff, B: 0x30, A: 0xFF})
+					//rect := &sdl.Rect{0, 0, (uint16)(window.W), (uint16)(window.H)}
+					//rend.FillRect(rect)
+					rend.FillRect(nil)
+					rend.Present()
+				})
+			case <-ctx.Done():
+				return
 			}
 		}
-		rend.SetDrawColor(sdl.Color{0x30, 0xff, 0x30, 0xFF, 0x00})
-		//rect := &sdl.Rect{0, 0, (uint16)(window.W), (uint16)(window.H)}
-		//rend.FillRect(rect)
-		rend.FillRect(nil)
-		rend.Present()
+	})
+
+	if err != nil {
+		panic(err)
 	}
 }
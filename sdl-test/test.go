@@ -40,17 +40,6 @@ func worm(in <-chan Point, out chan<- Point, draw chan<- Point) {
 	}
 }
 
-func RenderTextToTexture(r *sdl.Renderer, f *ttf.Font, text string, color sdl.Color) (*sdl.Texture, int, int) {
-	textw, texth, err := f.SizeText(text)
-	if err != nil {
-		log.Fatal(err)
-	}
-	txt_surface := f.RenderText_Blended(text, color)
-	txt_tex := r.CreateTextureFromSurface(txt_surface)
-	txt_surface.Free()
-	return txt_tex, textw, texth
-}
-
 func main() {
 	if sdl.Init(sdl.INIT_EVERYTHING) != 0 {
 		log.Fatal(sdl.GetError())
@@ -98,8 +87,9 @@ func main() {
 
 	font := ttf.OpenFont("./Fontin Sans.otf", 16)
 	defer font.Close()
-	txt_tex, _, _ := RenderTextToTexture(rend, font, "This is a test", sdl.Color{0x7F, 0xFF, 0x10, 0xFF})
-	defer txt_tex.Destroy()
+	glyphs := ttf.NewGlyphCache(font, rend)
+	defer glyphs.Free()
+	frame := 0
 
 	running := true
 
@@ -180,11 +170,15 @@ func main() {
 				}
 			}
 		}
-		rend.SetDrawColor(sdl.Color{0x30, 0x20, 0x19, 0xFF})
+		rend.SetDrawColor(sdl.Color{R: 0x30, G: 0x20, B: 0x19, A: 0xFF})
 		rend.FillRect(nil)
 		rend.Copy(tex, nil, nil)
 
-		rend.Copy(txt_tex, nil, nil)
+		text := fmt.Sprintf("This is a test\nFrame %d", frame)
+		if err := glyphs.Draw(text, 320, 8, sdl.Color{R: 0x7F, G: 0xFF, B: 0x10, A: 0xFF}, ttf.ALIGN_CENTER); err != nil {
+			log.Fatal(err)
+		}
+		frame++
 
 		rend.Present()
 	}
@@ -23,23 +23,108 @@ package sdl
   3. This notice may not be removed or altered from any source distribution.
 */
 
-import "unsafe"
+import (
+	"sync"
+	"unsafe"
+)
 
 /*
   #cgo pkg-config: sdl2
   #cgo linux LDFLAGS: -lrt
   #include <SDL2/SDL_audio.h>
 
-  extern void go_sdl2_audio_callback(void* userdata, Uint8* stream, int len);
+  extern int go_sdl2_audio_callback(uintptr_t userdata, Uint8* stream, int len);
 
-  SDL_AudioCallback go_sdl2_get_callback();
+  void* go_sdl2_set_audio_callback(SDL_AudioSpec* spec, uintptr_t handle);
+  void go_sdl2_set_audio_silence(void* userdata, Uint8 silence);
 */
 import "C"
 
+// Called on the audio thread to fill (or, for capture devices, drain)
+// the stream. The stream slice points into SDL's audio buffer and is
+// only valid until the callback returns.
+type AudioCallback func(stream []byte)
+
+// The callback of an open audio device: its handle, and the C userdata
+// passed to SDL along with it.
+type audioCallback struct {
+	h        uintptr
+	userdata unsafe.Pointer
+}
+
+// The callbacks of the open audio devices. The legacy OpenAudio device
+// always has ID 1.
+var audioCallbacks = struct {
+	sync.Mutex
+	m map[AudioDeviceID]audioCallback
+}{m: make(map[AudioDeviceID]audioCallback)}
+
+// Returns 0 if the callback has been released, so that the C side
+// fills the stream with silence.
+//
 //export go_sdl2_audio_callback
-func go_sdl2_audio_callback(userdata unsafe.Pointer, stream *C.Uint8, length C.int) {
-	cb := *(*func(*byte, int))(userdata)
-	cb((*byte)(stream), int(length))
+func go_sdl2_audio_callback(userdata C.uintptr_t, stream *C.Uint8, length C.int) C.int {
+	cb, _ := handles.get(uintptr(userdata)).(AudioCallback)
+	if cb == nil {
+		return 0
+	}
+	n := int(length)
+	cb((*[1 << 30]byte)(unsafe.Pointer(stream))[:n:n])
+	return 1
+}
+
+// Registers callback for a device about to be opened and fills in the
+// callback fields of cspec. A nil callback leaves the device in queue
+// mode, with a zero audioCallback.
+func setAudioCallback(cspec *C.SDL_AudioSpec, callback AudioCallback) audioCallback {
+	var cb audioCallback
+	if callback != nil {
+		cb.h = handles.put(callback)
+	}
+	cb.userdata = C.go_sdl2_set_audio_callback(cspec, C.uintptr_t(cb.h))
+	if cb.h != 0 && cb.userdata == nil {
+		// Out of memory; the device would not call back anyway.
+		handles.delete(cb.h)
+		cb.h = 0
+	}
+	return cb
+}
+
+// Associates a callback with an opened device and sets the silence
+// value of its format, or releases the callback if the device failed
+// to open.
+func bindAudioCallback(dev AudioDeviceID, cb audioCallback, silence C.Uint8) {
+	if cb.h == 0 {
+		return
+	}
+	if dev == 0 {
+		cb.free()
+		return
+	}
+	C.go_sdl2_set_audio_silence(cb.userdata, silence)
+	audioCallbacks.Lock()
+	audioCallbacks.m[dev] = cb
+	audioCallbacks.Unlock()
+}
+
+// Removes the callback of a device about to be closed from the
+// registry, so that a device opened with the same ID while it closes
+// keeps its own. The callback must be freed once the device is closed.
+func takeAudioCallback(dev AudioDeviceID) audioCallback {
+	audioCallbacks.Lock()
+	cb := audioCallbacks.m[dev]
+	delete(audioCallbacks.m, dev)
+	audioCallbacks.Unlock()
+	return cb
+}
+
+// Releases the handle and userdata of a callback no longer used by any
+// device.
+func (cb audioCallback) free() {
+	if cb.h != 0 {
+		handles.delete(cb.h)
+		C.SDL_free(cb.userdata)
+	}
 }
 
 type AudioDeviceID uint32
@@ -74,6 +159,10 @@ const (
 	AUDIO_ALLOW_CHANNELS_CHANGE  = C.SDL_AUDIO_ALLOW_CHANNELS_CHANGE
 	AUDIO_ALLOW_ANY_CHANGE       = C.SDL_AUDIO_ALLOW_ANY_CHANGE
 
+	AUDIO_STOPPED = C.SDL_AUDIO_STOPPED
+	AUDIO_PLAYING = C.SDL_AUDIO_PLAYING
+	AUDIO_PAUSED  = C.SDL_AUDIO_PAUSED
+
 	MIX_MAXVOLUME = 128
 )
 
@@ -138,50 +227,84 @@ func GetCurrentAudioDriver() string {
 	return C.GoString(C.SDL_GetCurrentAudioDriver())
 }
 
-func OpenAudio(desired, obtained *AudioSpec, callback func(*byte, int)) bool {
-	var cdesired C.SDL_AudioSpec
+func (spec *AudioSpec) toC() C.SDL_AudioSpec {
+	var cspec C.SDL_AudioSpec
+	cspec.freq = C.int(spec.Freq)
+	cspec.format = C.SDL_AudioFormat(int(spec.Format))
+	cspec.channels = C.Uint8(spec.Channels)
+	cspec.samples = C.Uint16(spec.Samples)
+	cspec.size = C.Uint32(0)
+	return cspec
+}
+
+func (spec *AudioSpec) fromC(cspec *C.SDL_AudioSpec) {
+	spec.Freq = int32(cspec.freq)
+	spec.Format = AudioFormat(int(cspec.format))
+	spec.Channels = uint8(cspec.channels)
+	spec.Silence = uint8(cspec.silence)
+	spec.Samples = uint16(cspec.samples)
+	spec.Size = uint32(cspec.size)
+}
+
+// Opens the legacy audio device (ID 1). The callback is called on the
+// audio thread whenever more data is needed; it may be nil to use
+// QueueAudio instead.
+func OpenAudio(desired, obtained *AudioSpec, callback AudioCallback) bool {
+	cdesired := desired.toC()
 	var cobtained C.SDL_AudioSpec
-	cdesired.freq = C.int(desired.Freq)
-	cdesired.format = C.SDL_AudioFormat(int(desired.Format))
-	cdesired.channels = C.Uint8(desired.Channels)
-	cdesired.samples = C.Uint16(desired.Samples)
-	cdesired.size = C.Uint32(0)
-	cdesired.callback = C.go_sdl2_get_callback()
-	cdesired.userdata = unsafe.Pointer(&callback)
+	cb := setAudioCallback(&cdesired, callback)
 	ret := C.SDL_OpenAudio(&cdesired, &cobtained)
+	if int(ret) != 0 {
+		bindAudioCallback(0, cb, 0)
+		return false
+	}
+	bindAudioCallback(1, cb, cobtained.silence)
 	if obtained != nil {
-		obtained.Freq = int32(cobtained.freq)
-		obtained.Format = AudioFormat(int(cobtained.format))
-		obtained.Channels = uint8(cobtained.channels)
-		obtained.Silence = uint8(cobtained.silence)
-		obtained.Samples = uint16(cobtained.samples)
-		obtained.Size = uint32(cobtained.size)
+		obtained.fromC(&cobtained)
 	}
-	return int(ret) == 0
-}
-
-func OpenAudioDevice(device string, iscapture bool, desired, obtained *AudioSpec, allowed_changes bool, callback func(*byte, int)) bool {
-	var cdesired C.SDL_AudioSpec
+	return true
+}
+
+// Opens a specific audio device. An empty device name requests the
+// most reasonable default device. allowedChanges is a combination of
+// AUDIO_ALLOW_* flags. The callback is called on the audio thread
+// whenever more data is needed, or, for capture devices, is available;
+// it may be nil to use QueueAudio/DequeueAudio instead. Several devices
+// may be open at once, each with its own callback.
+func OpenAudioDevice(device string, iscapture bool, desired, obtained *AudioSpec, allowedChanges int, callback AudioCallback) (AudioDeviceID, error) {
+	var cdevice *C.char
+	if device != "" {
+		cdevice = C.CString(device)
+		defer C.free(unsafe.Pointer(cdevice))
+	}
+	cdesired := desired.toC()
 	var cobtained C.SDL_AudioSpec
-	cdevice := C.CString(device)
-	defer C.free(unsafe.Pointer(cdevice))
-	cdesired.freq = C.int(desired.Freq)
-	cdesired.format = C.SDL_AudioFormat(int(desired.Format))
-	cdesired.channels = C.Uint8(desired.Channels)
-	cdesired.samples = C.Uint16(desired.Samples)
-	cdesired.size = C.Uint32(0)
-	cdesired.callback = C.go_sdl2_get_callback()
-	cdesired.userdata = unsafe.Pointer(&callback)
-	ret := C.SDL_OpenAudioDevice(cdevice, C.int(bool2int(iscapture)), &cdesired, &cobtained, C.int(bool2int(allowed_changes)))
+	cb := setAudioCallback(&cdesired, callback)
+	dev := AudioDeviceID(C.SDL_OpenAudioDevice(cdevice, C.int(bool2int(iscapture)), &cdesired, &cobtained, C.int(allowedChanges)))
+	bindAudioCallback(dev, cb, cobtained.silence)
+	if dev == 0 {
+		return 0, NewSDLError()
+	}
 	if obtained != nil {
-		obtained.Freq = int32(cobtained.freq)
-		obtained.Format = AudioFormat(int(cobtained.format))
-		obtained.Channels = uint8(cobtained.channels)
-		obtained.Silence = uint8(cobtained.silence)
-		obtained.Samples = uint16(cobtained.samples)
-		obtained.Size = uint32(cobtained.size)
+		obtained.fromC(&cobtained)
 	}
-	return int(ret) == 0
+	return dev, nil
+}
+
+// Closes the legacy audio device opened with OpenAudio and releases its
+// callback.
+func CloseAudio() {
+	cb := takeAudioCallback(1)
+	C.SDL_CloseAudio()
+	cb.free()
+}
+
+// Closes an audio device opened with OpenAudioDevice and releases its
+// callback. The callback is not called anymore once this returns.
+func CloseAudioDevice(dev AudioDeviceID) {
+	cb := takeAudioCallback(dev)
+	C.SDL_CloseAudioDevice(C.SDL_AudioDeviceID(dev))
+	cb.free()
 }
 
 func GetNumAudioDevices(iscapture int) int {
@@ -208,23 +331,128 @@ func PauseAudioDevice(dev AudioDeviceID, pause_on bool) {
 	C.SDL_PauseAudioDevice(C.SDL_AudioDeviceID(int(dev)), C.int(bool2int(pause_on)))
 }
 
-type WAVData struct {
-	Spec     *AudioSpec
-	AudioBuf *byte
-	AudioLen uint32
+// Queues more audio on a device opened without a callback. The data
+// is copied, so the slice may be reused right away.
+func QueueAudio(dev AudioDeviceID, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if C.SDL_QueueAudio(C.SDL_AudioDeviceID(dev), unsafe.Pointer(&data[0]), C.Uint32(len(data))) != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
+// Reads captured audio from a capture device opened without a callback
+// into data. Returns the number of bytes read, which may be less than
+// len(data) if not enough audio is available.
+func DequeueAudio(dev AudioDeviceID, data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	return int(C.SDL_DequeueAudio(C.SDL_AudioDeviceID(dev), unsafe.Pointer(&data[0]), C.Uint32(len(data))))
 }
 
-func LoadWAV(file string, spec *AudioSpec) *WAVData {
-	// magic
-	return nil
+// Gets the number of bytes of audio still queued, either waiting to be
+// played or, for capture devices, waiting to be dequeued.
+func GetQueuedAudioSize(dev AudioDeviceID) uint32 {
+	return uint32(C.SDL_GetQueuedAudioSize(C.SDL_AudioDeviceID(dev)))
 }
 
-func LoadWAV_RW(src *RWops, freesrc bool, spec *AudioSpec) *WAVData {
-	return nil
+// Drops any audio still queued on a device.
+func ClearQueuedAudio(dev AudioDeviceID) {
+	C.SDL_ClearQueuedAudio(C.SDL_AudioDeviceID(dev))
+}
+
+// An audio device opened in queue mode: audio is pushed to it with
+// QueueAudio or, for capture devices, pulled from it with DequeueAudio
+// instead of being exchanged through a callback.
+type AudioDevice struct {
+	ID      AudioDeviceID
+	Spec    AudioSpec // The spec actually obtained when opening the device
+	Capture bool
+}
+
+// Opens an audio device in queue mode. The device starts out paused.
+// See OpenAudioDevice for the meaning of the arguments.
+func OpenQueuedAudioDevice(device string, iscapture bool, desired *AudioSpec, allowedChanges int) (*AudioDevice, error) {
+	d := &AudioDevice{Capture: iscapture}
+	id, err := OpenAudioDevice(device, iscapture, desired, &d.Spec, allowedChanges, nil)
+	if err != nil {
+		return nil, err
+	}
+	d.ID = id
+	return d, nil
+}
+
+// Queues more audio to be played. Only valid for playback devices.
+func (d *AudioDevice) QueueAudio(data []byte) error {
+	return QueueAudio(d.ID, data)
+}
+
+// Reads captured audio into data. Only valid for capture devices.
+// Returns the number of bytes read.
+func (d *AudioDevice) DequeueAudio(data []byte) int {
+	return DequeueAudio(d.ID, data)
+}
+
+// Gets the number of bytes of audio still queued.
+func (d *AudioDevice) GetQueuedAudioSize() uint32 {
+	return GetQueuedAudioSize(d.ID)
+}
+
+// Drops any audio still queued.
+func (d *AudioDevice) ClearQueuedAudio() {
+	ClearQueuedAudio(d.ID)
 }
 
-func (w *WAVData) Free() {
-	C.SDL_FreeWAV((*C.Uint8)(unsafe.Pointer(w.AudioBuf)))
+// Pauses or unpauses playback or capture.
+func (d *AudioDevice) Pause(pause_on bool) {
+	PauseAudioDevice(d.ID, pause_on)
+}
+
+// Gets the status of the device: AUDIO_STOPPED, AUDIO_PLAYING or
+// AUDIO_PAUSED.
+func (d *AudioDevice) Status() int {
+	return GetAudioDeviceStatus(d.ID)
+}
+
+// Closes the device.
+func (d *AudioDevice) Close() {
+	CloseAudioDevice(d.ID)
+}
+
+// Loads a WAVE file. Returns the spec of the audio and the audio data,
+// copied into Go memory.
+func LoadWAV(file string) (AudioSpec, []byte, error) {
+	src := RWFromFile(file, "rb")
+	if src == nil {
+		return AudioSpec{}, nil, NewSDLError()
+	}
+	return LoadWAV_RW(src, true)
+}
+
+// Loads WAVE data from an SDL data stream, such as one created with
+// RWFromMem. If freesrc is true, the stream is closed afterwards even
+// on error. Returns the spec of the audio and the audio data, copied
+// into Go memory.
+func LoadWAV_RW(src *RWops, freesrc bool) (AudioSpec, []byte, error) {
+	var cspec C.SDL_AudioSpec
+	var cbuf *C.Uint8
+	var clen C.Uint32
+	ret := C.SDL_LoadWAV_RW(src.cRWops, C.int(bool2int(freesrc)), &cspec, &cbuf, &clen)
+	if freesrc {
+		src.cRWops = nil
+		src.mem = nil
+	}
+	if ret == nil {
+		return AudioSpec{}, nil, NewSDLError()
+	}
+	defer C.SDL_FreeWAV(cbuf)
+
+	var spec AudioSpec
+	spec.fromC(&cspec)
+	return spec, C.GoBytes(unsafe.Pointer(cbuf), C.int(clen)), nil
 }
 
 type AudioFilter func(*AudioCVT, AudioFormat)
@@ -245,13 +473,130 @@ func BuildAudioCVT(src_format AudioFormat, src_channels uint8, src_rate int,
 	return rcvt, int(ret)
 }
 
+// Converts the audio in cvt.Buf, which is replaced by the converted
+// audio. The length of cvt.Buf must be a multiple of the source frame
+// size.
 func ConvertAudio(cvt *AudioCVT) bool {
-	//var buf2 [uint(cvt.cAudioCVT.len) * uint(cvt.cAudioCVT.len_mult)]byte
-	//cvt.Buf = buf2
-	//cvt.cAudioCVT.buf = (*C.Uint8)(unsafe.Pointer(&cvt.Buf[0]))
-	//	cvt.cAudioCVT.len = C.int(len(buf))
-	ret := C.SDL_ConvertAudio(cvt.cAudioCVT)
-	return int(ret) == 0
+	if cvt.cAudioCVT.needed == 0 {
+		return true
+	}
+	buf, err := convertAudio(cvt.cAudioCVT, cvt.Buf)
+	if err != nil {
+		return false
+	}
+	cvt.Buf = buf
+	return true
+}
+
+// Runs a conversion set up by SDL_BuildAudioCVT on src. SDL converts in
+// place in a buffer that may need to be several times larger than src,
+// and keeps a pointer to it in cvt, so it has to live in C memory.
+func convertAudio(cvt *C.SDL_AudioCVT, src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return []byte{}, nil
+	}
+	cbuf := C.malloc(C.size_t(len(src) * int(cvt.len_mult)))
+	if cbuf == nil {
+		return nil, &SDLError{"ConvertAudio: out of memory"}
+	}
+	defer C.free(cbuf)
+	copy((*[1 << 30]byte)(cbuf)[:len(src)], src)
+
+	cvt.buf = (*C.Uint8)(cbuf)
+	cvt.len = C.int(len(src))
+	ret := C.SDL_ConvertAudio(cvt)
+	cvt.buf = nil
+	if ret != 0 {
+		return nil, NewSDLError()
+	}
+	return C.GoBytes(cbuf, cvt.len_cvt), nil
+}
+
+// Converts PCM audio from one format, channel count and rate to
+// another, as given by the Format, Channels and Freq fields of the
+// specs. The length of src must be a multiple of the source frame
+// size. Returns a new slice holding the converted audio, or a copy of
+// src if no conversion is needed.
+func ConvertPCM(src []byte, from, to AudioSpec) ([]byte, error) {
+	var cvt C.SDL_AudioCVT
+	ret := C.SDL_BuildAudioCVT(&cvt,
+		C.SDL_AudioFormat(from.Format), C.Uint8(from.Channels), C.int(from.Freq),
+		C.SDL_AudioFormat(to.Format), C.Uint8(to.Channels), C.int(to.Freq))
+	if ret < 0 {
+		return nil, NewSDLError()
+	}
+	if ret == 0 {
+		return append([]byte{}, src...), nil
+	}
+	return convertAudio(&cvt, src)
+}
+
+// An AudioStream converts audio incrementally: put audio in one format
+// into it and get audio in another format out, in chunks of any size.
+type AudioStream struct {
+	cAudioStream *C.SDL_AudioStream
+}
+
+// Creates a stream converting from the Format, Channels and Freq of one
+// spec to those of another.
+func NewAudioStream(from, to AudioSpec) (*AudioStream, error) {
+	cstream := C.SDL_NewAudioStream(
+		C.SDL_AudioFormat(from.Format), C.Uint8(from.Channels), C.int(from.Freq),
+		C.SDL_AudioFormat(to.Format), C.Uint8(to.Channels), C.int(to.Freq))
+	if cstream == nil {
+		return nil, NewSDLError()
+	}
+	return &AudioStream{cstream}, nil
+}
+
+// Adds source audio to the stream. The data is copied.
+func (s *AudioStream) Put(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if C.SDL_AudioStreamPut(s.cAudioStream, unsafe.Pointer(&data[0]), C.int(len(data))) != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
+// Gets converted audio from the stream into buf. Returns the number of
+// bytes read, which is 0 if no converted audio is available yet.
+func (s *AudioStream) Get(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	n := int(C.SDL_AudioStreamGet(s.cAudioStream, unsafe.Pointer(&buf[0]), C.int(len(buf))))
+	if n < 0 {
+		return 0, NewSDLError()
+	}
+	return n, nil
+}
+
+// Gets the number of converted bytes available to Get.
+func (s *AudioStream) Available() int {
+	return int(C.SDL_AudioStreamAvailable(s.cAudioStream))
+}
+
+// Tells the stream that no more source audio is coming, so that all of
+// it is converted and made available, including audio the resampler
+// was holding back.
+func (s *AudioStream) Flush() error {
+	if C.SDL_AudioStreamFlush(s.cAudioStream) != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
+// Drops all audio in the stream, both converted and not.
+func (s *AudioStream) Clear() {
+	C.SDL_AudioStreamClear(s.cAudioStream)
+}
+
+// Frees the stream.
+func (s *AudioStream) Free() {
+	C.SDL_FreeAudioStream(s.cAudioStream)
+	s.cAudioStream = nil
 }
 
 func MixAudio(dst, src []byte, volume int) {
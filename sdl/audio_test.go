@@ -0,0 +1,87 @@
+package sdl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testAudioSpec = AudioSpec{Freq: 44100, Format: AUDIO_S16SYS, Channels: 2, Samples: 512}
+
+// Initializes audio with the given driver, so the tests run on
+// headless machines.
+func initTestAudio(t *testing.T, driver string) {
+	t.Setenv("SDL_AUDIODRIVER", driver)
+	if Init(INIT_AUDIO) != 0 {
+		t.Skip(driver + " audio driver unavailable: " + GetError())
+	}
+	t.Cleanup(Quit)
+}
+
+func TestQueueAudio(t *testing.T) {
+	for _, driver := range []string{"dummy", "disk"} {
+		t.Run(driver, func(t *testing.T) {
+			if driver == "disk" {
+				t.Setenv("SDL_DISKAUDIOFILE", filepath.Join(t.TempDir(), "out.raw"))
+			}
+			initTestAudio(t, driver)
+
+			// The device stays paused, so nothing is consumed.
+			d, err := OpenQueuedAudioDevice("", false, &testAudioSpec, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer d.Close()
+
+			if n := d.GetQueuedAudioSize(); n != 0 {
+				t.Fatalf("GetQueuedAudioSize() = %d on a new device, want 0", n)
+			}
+			buf := make([]byte, 4096)
+			for i := 1; i <= 2; i++ {
+				if err := d.QueueAudio(buf); err != nil {
+					t.Fatal(err)
+				}
+				if n := d.GetQueuedAudioSize(); n != uint32(i*len(buf)) {
+					t.Errorf("GetQueuedAudioSize() = %d after queueing %d bytes", n, i*len(buf))
+				}
+			}
+
+			d.ClearQueuedAudio()
+			if n := d.GetQueuedAudioSize(); n != 0 {
+				t.Errorf("GetQueuedAudioSize() = %d after ClearQueuedAudio, want 0", n)
+			}
+		})
+	}
+}
+
+func TestDequeueAudio(t *testing.T) {
+	in := filepath.Join(t.TempDir(), "in.raw")
+	if err := os.WriteFile(in, make([]byte, 64*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SDL_DISKAUDIOFILEIN", in)
+	initTestAudio(t, "disk")
+
+	d, err := OpenQueuedAudioDevice("", true, &testAudioSpec, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if !d.Capture {
+		t.Error("Capture = false for a capture device")
+	}
+	d.Pause(false)
+
+	buf := make([]byte, 4096)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if n := d.DequeueAudio(buf); n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("DequeueAudio returned no data from the disk driver")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
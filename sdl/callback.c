@@ -20,9 +20,67 @@
 */
 
 #include <SDL2/SDL_audio.h>
+#include <SDL2/SDL_events.h>
 
-extern void go_sdl2_audio_callback(void* userdata, Uint8* stream, int len);
+extern int go_sdl2_audio_callback(uintptr_t userdata, Uint8* stream, int len);
+extern int go_sdl2_event_filter(uintptr_t userdata, SDL_Event* event);
 
-SDL_AudioCallback go_sdl2_get_callback() {
-	return &go_sdl2_audio_callback;
+/* The userdata of Go audio callbacks. The Go side passes table handles
+   rather than pointers; the silence value lets the stream be filled
+   with silence if the handle has already been released. */
+typedef struct {
+	uintptr_t handle;
+	Uint8 silence;
+} go_sdl2_audio_userdata;
+
+static void go_sdl2_audio_trampoline(void* userdata, Uint8* stream, int len) {
+	go_sdl2_audio_userdata* data = userdata;
+	if (!go_sdl2_audio_callback(data->handle, stream, len)) {
+		SDL_memset(stream, data->silence, len);
+	}
+}
+
+void* go_sdl2_set_audio_callback(SDL_AudioSpec* spec, uintptr_t handle) {
+	go_sdl2_audio_userdata* data = NULL;
+	if (handle != 0) {
+		data = SDL_malloc(sizeof(*data));
+	}
+	if (data == NULL) {
+		spec->callback = NULL;
+		spec->userdata = NULL;
+		return NULL;
+	}
+	data->handle = handle;
+	data->silence = 0;
+	spec->callback = &go_sdl2_audio_trampoline;
+	spec->userdata = data;
+	return data;
+}
+
+void go_sdl2_set_audio_silence(void* userdata, Uint8 silence) {
+	((go_sdl2_audio_userdata*)userdata)->silence = silence;
+}
+
+static int go_sdl2_event_filter_trampoline(void* userdata, SDL_Event* event) {
+	return go_sdl2_event_filter((uintptr_t)userdata, event);
+}
+
+void go_sdl2_set_event_filter(uintptr_t handle) {
+	if (handle == 0) {
+		SDL_SetEventFilter(NULL, NULL);
+	} else {
+		SDL_SetEventFilter(&go_sdl2_event_filter_trampoline, (void*)handle);
+	}
+}
+
+void go_sdl2_add_event_watch(uintptr_t handle) {
+	SDL_AddEventWatch(&go_sdl2_event_filter_trampoline, (void*)handle);
+}
+
+void go_sdl2_del_event_watch(uintptr_t handle) {
+	SDL_DelEventWatch(&go_sdl2_event_filter_trampoline, (void*)handle);
+}
+
+void go_sdl2_filter_events(uintptr_t handle) {
+	SDL_FilterEvents(&go_sdl2_event_filter_trampoline, (void*)handle);
 }
@@ -337,6 +337,12 @@ const (
 	PIXELFORMAT_UYVY        = C.SDL_PIXELFORMAT_UYVY
 	PIXELFORMAT_YVYU        = C.SDL_PIXELFORMAT_YVYU
 
+	// byte-order aliases for the packed 32-bit formats
+	PIXELFORMAT_RGBA32 = C.SDL_PIXELFORMAT_RGBA32
+	PIXELFORMAT_ARGB32 = C.SDL_PIXELFORMAT_ARGB32
+	PIXELFORMAT_BGRA32 = C.SDL_PIXELFORMAT_BGRA32
+	PIXELFORMAT_ABGR32 = C.SDL_PIXELFORMAT_ABGR32
+
 	ALPHA_OPAQUE      = C.SDL_ALPHA_OPAQUE
 	ALPHA_TRANSPARENT = C.SDL_ALPHA_TRANSPARENT
 
@@ -0,0 +1,219 @@
+/*
+Package dispatch runs the SDL event loop on the main OS thread and
+delivers events to the rest of the program over Go channels.
+
+Most SDL calls (video, rendering, event handling) must be made from the
+thread that initialized SDL, which on several platforms has to be the
+main thread. Importing this package locks the main goroutine to the
+main OS thread; call Run from main() and do everything else from the
+function passed to it, sending SDL work back to the main thread with
+Do or Post:
+
+	func main() {
+		err := dispatch.Run(context.Background(), sdl.INIT_VIDEO, func(ctx context.Context, d *dispatch.Dispatcher) {
+			for {
+				select {
+				case ev := <-d.Events():
+					...
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+		...
+	}
+*/
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+
+	"github.com/krig/Go-SDL2/sdl"
+)
+
+func init() {
+	// init functions run on the main goroutine, which is still on the
+	// main thread at this point. Keep it there.
+	runtime.LockOSThread()
+}
+
+// Returned by Do and Post once the event loop has stopped.
+var ErrStopped = errors.New("dispatch: event loop has stopped")
+
+// How long the event loop waits for an event before checking for jobs
+// and cancellation again, in milliseconds. Jobs and cancellation also
+// wake the loop up directly, so this only bounds the latency if that
+// wake-up event is lost.
+const waitTimeout = 100
+
+// A Dispatcher owns the SDL event loop while Run is executing.
+type Dispatcher struct {
+	events   chan sdl.TypedEvent
+	pending  chan struct{} // Signalled after jobs are queued
+	wakeType uint32
+
+	mu      sync.Mutex
+	jobs    []func()
+	stopped bool
+}
+
+// Run initializes SDL with the given flags, starts f in a new goroutine
+// and then runs the event loop on the calling goroutine, which must be
+// the main goroutine. The context passed to f is cancelled when ctx is
+// cancelled or f returns; the loop then stops, Run waits for f to
+// return, shuts down SDL and returns.
+func Run(ctx context.Context, flags uint32, f func(ctx context.Context, d *Dispatcher)) error {
+	if sdl.Init(flags) != 0 {
+		return sdl.NewSDLError()
+	}
+	defer sdl.Quit()
+
+	d := &Dispatcher{
+		events:   make(chan sdl.TypedEvent, 64),
+		pending:  make(chan struct{}, 1),
+		wakeType: sdl.RegisterEvents(1),
+	}
+	if d.wakeType == 0xFFFFFFFF {
+		return sdl.NewSDLError()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer cancel()
+		f(ctx, d)
+	}()
+	woken := make(chan struct{})
+	go func() {
+		defer close(woken)
+		<-ctx.Done()
+		d.wake()
+	}()
+
+	d.loop(ctx)
+
+	// Jobs queued before the loop stopped still run, so that nobody is
+	// left waiting in Do.
+	d.mu.Lock()
+	d.stopped = true
+	jobs := d.jobs
+	d.jobs = nil
+	d.mu.Unlock()
+	for _, job := range jobs {
+		job()
+	}
+	<-finished
+	<-woken
+	return nil
+}
+
+// Events returns the channel on which events are delivered, decoded
+// with Event.Get(). Events are delivered in order; the loop keeps
+// running jobs while it waits for the receiver.
+func (d *Dispatcher) Events() <-chan sdl.TypedEvent {
+	return d.events
+}
+
+// Do runs job on the main thread and waits for it to finish.  Jobs
+// already run on the main thread, so calling Do from inside a job
+// deadlocks: the loop is busy running the caller and never gets to the
+// new job.  Call the function directly there instead, or use Post.
+func (d *Dispatcher) Do(job func()) error {
+	finished := make(chan struct{})
+	if err := d.Post(func() {
+		defer close(finished)
+		job()
+	}); err != nil {
+		return err
+	}
+	<-finished
+	return nil
+}
+
+// Post queues job to run on the main thread without waiting for it.
+func (d *Dispatcher) Post(job func()) error {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return ErrStopped
+	}
+	d.jobs = append(d.jobs, job)
+	d.mu.Unlock()
+
+	// Only wake the loop once the job is queued, so it can not go back
+	// to waiting without seeing it.
+	select {
+	case d.pending <- struct{}{}:
+	default:
+	}
+	d.wake()
+	return nil
+}
+
+// Pushes an event which makes the loop return from WaitTimeout.
+func (d *Dispatcher) wake() {
+	var event sdl.Event
+	event.Type = d.wakeType
+	sdl.PushEvent(&event)
+}
+
+func (d *Dispatcher) loop(ctx context.Context) {
+	event := &sdl.Event{}
+	for {
+		for ready := event.WaitTimeout(waitTimeout); ready; ready = event.Poll() {
+			if event.Type == d.wakeType {
+				continue
+			}
+			if !d.deliver(ctx, event.Get()) {
+				return
+			}
+		}
+		if !d.runJobs(ctx) {
+			return
+		}
+	}
+}
+
+// Runs queued jobs until there are none left. Returns false once ctx
+// is done.
+func (d *Dispatcher) runJobs(ctx context.Context) bool {
+	for {
+		if ctx.Err() != nil {
+			return false
+		}
+		d.mu.Lock()
+		jobs := d.jobs
+		d.jobs = nil
+		d.mu.Unlock()
+		if len(jobs) == 0 {
+			return true
+		}
+		for _, job := range jobs {
+			job()
+		}
+	}
+}
+
+// Hands ev to the receiver, running jobs while waiting so that a
+// receiver blocked in Do can not deadlock the loop. Returns false once
+// ctx is done.
+func (d *Dispatcher) deliver(ctx context.Context, ev sdl.TypedEvent) bool {
+	for {
+		select {
+		case d.events <- ev:
+			return true
+		case <-d.pending:
+			if !d.runJobs(ctx) {
+				return false
+			}
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
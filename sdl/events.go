@@ -26,17 +26,34 @@ package sdl
 
 // #cgo pkg-config: sdl2
 // #include <SDL2/SDL.h>
+//
+// void go_sdl2_set_event_filter(uintptr_t handle);
+// void go_sdl2_add_event_watch(uintptr_t handle);
+// void go_sdl2_del_event_watch(uintptr_t handle);
+// void go_sdl2_filter_events(uintptr_t handle);
 import "C"
+import (
+	"sync"
+	"unsafe"
+)
 
 const (
 	// event types
+	FIRSTEVENT               = C.SDL_FIRSTEVENT
 	QUIT                     = C.SDL_QUIT
+	APP_TERMINATING          = C.SDL_APP_TERMINATING
+	APP_LOWMEMORY            = C.SDL_APP_LOWMEMORY
+	APP_WILLENTERBACKGROUND  = C.SDL_APP_WILLENTERBACKGROUND
+	APP_DIDENTERBACKGROUND   = C.SDL_APP_DIDENTERBACKGROUND
+	APP_WILLENTERFOREGROUND  = C.SDL_APP_WILLENTERFOREGROUND
+	APP_DIDENTERFOREGROUND   = C.SDL_APP_DIDENTERFOREGROUND
 	WINDOWEVENT              = C.SDL_WINDOWEVENT
 	SYSWMEVENT               = C.SDL_SYSWMEVENT
 	KEYDOWN                  = C.SDL_KEYDOWN
 	KEYUP                    = C.SDL_KEYUP
 	TEXTEDITING              = C.SDL_TEXTEDITING
 	TEXTINPUT                = C.SDL_TEXTINPUT
+	KEYMAPCHANGED            = C.SDL_KEYMAPCHANGED
 	MOUSEMOTION              = C.SDL_MOUSEMOTION
 	MOUSEBUTTONDOWN          = C.SDL_MOUSEBUTTONDOWN
 	MOUSEBUTTONUP            = C.SDL_MOUSEBUTTONUP
@@ -62,7 +79,15 @@ const (
 	MULTIGESTURE             = C.SDL_MULTIGESTURE
 	CLIPBOARDUPDATE          = C.SDL_CLIPBOARDUPDATE
 	DROPFILE                 = C.SDL_DROPFILE
+	DROPTEXT                 = C.SDL_DROPTEXT
+	DROPBEGIN                = C.SDL_DROPBEGIN
+	DROPCOMPLETE             = C.SDL_DROPCOMPLETE
+	AUDIODEVICEADDED         = C.SDL_AUDIODEVICEADDED
+	AUDIODEVICEREMOVED       = C.SDL_AUDIODEVICEREMOVED
+	RENDER_TARGETS_RESET     = C.SDL_RENDER_TARGETS_RESET
+	RENDER_DEVICE_RESET      = C.SDL_RENDER_DEVICE_RESET
 	USEREVENT                = C.SDL_USEREVENT
+	LASTEVENT                = C.SDL_LASTEVENT
 
 	// event state
 	QUERY   = C.SDL_QUERY
@@ -70,6 +95,11 @@ const (
 	DISABLE = C.SDL_DISABLE
 	ENABLE  = C.SDL_ENABLE
 
+	// event actions for PeepEvents
+	ADDEVENT  = C.SDL_ADDEVENT
+	PEEKEVENT = C.SDL_PEEKEVENT
+	GETEVENT  = C.SDL_GETEVENT
+
 	// constants
 	TEXTEDITINGEVENT_TEXT_SIZE = 32
 	TEXTINPUTEVENT_TEXT_SIZE   = 32
@@ -95,12 +125,39 @@ func (event *Event) WaitTimeout(timeout int) bool {
 	return ret != 0
 }
 
-// Adapts the event to its type
-func (event *Event) Get() interface{} {
+// Implemented by every event type returned from Event.Get(), so the
+// result can be inspected without a type switch.
+type TypedEvent interface {
+	GetType() uint32
+	GetTimestamp() uint32
+}
+
+// An event used to request a file open by the system (DROPFILE), or
+// text dropped on a window (DROPTEXT). DROPBEGIN and DROPCOMPLETE carry
+// an empty File. These events are disabled by default; you can enable
+// them with EventState(). Unlike the C API, the file name does not
+// need to be freed: Event.Get() copies it and frees the C string, so
+// it must only be called on drop events taken from the queue, never
+// from an EventFilter or event watch.
+type DropEvent struct {
+	Type      uint32
+	Timestamp uint32
+	File      string
+	WindowID  uint32
+}
+
+// Adapts the event to its type. Events that are not known to this
+// binding are returned as a CommonEvent.
+func (event *Event) Get() TypedEvent {
 	switch event.Type {
 	case QUIT:
 		return *(*QuitEvent)(cast(event))
 
+	case APP_TERMINATING, APP_LOWMEMORY,
+		APP_WILLENTERBACKGROUND, APP_DIDENTERBACKGROUND,
+		APP_WILLENTERFOREGROUND, APP_DIDENTERFOREGROUND:
+		return *(*CommonEvent)(cast(event))
+
 	case WINDOWEVENT:
 		return *(*WindowEvent)(cast(event))
 
@@ -116,6 +173,9 @@ func (event *Event) Get() interface{} {
 	case TEXTINPUT:
 		return *(*TextInputEvent)(cast(event))
 
+	case KEYMAPCHANGED:
+		return *(*CommonEvent)(cast(event))
+
 	case MOUSEBUTTONDOWN, MOUSEBUTTONUP:
 		return *(*MouseButtonEvent)(cast(event))
 
@@ -140,20 +200,109 @@ func (event *Event) Get() interface{} {
 	case JOYDEVICEADDED, JOYDEVICEREMOVED:
 		return *(*JoyDeviceEvent)(cast(event))
 
+	case CONTROLLERAXISMOTION:
+		return *(*ControllerAxisEvent)(cast(event))
+
+	case CONTROLLERBUTTONDOWN, CONTROLLERBUTTONUP:
+		return *(*ControllerButtonEvent)(cast(event))
+
+	case CONTROLLERDEVICEADDED, CONTROLLERDEVICEREMOVED, CONTROLLERDEVICEREMAPPED:
+		return *(*ControllerDeviceEvent)(cast(event))
+
+	case AUDIODEVICEADDED, AUDIODEVICEREMOVED:
+		return *(*AudioDeviceEvent)(cast(event))
+
+	case FINGERDOWN, FINGERUP, FINGERMOTION:
+		return *(*TouchFingerEvent)(cast(event))
+
+	case DOLLARGESTURE, DOLLARRECORD:
+		return *(*DollarGestureEvent)(cast(event))
+
+	case MULTIGESTURE:
+		return *(*MultiGestureEvent)(cast(event))
+
 	case CLIPBOARDUPDATE:
 		return *(*CommonEvent)(cast(event))
 
-	case DROPFILE:
-		return *(*DropEvent)(cast(event))
+	case DROPFILE, DROPTEXT, DROPBEGIN, DROPCOMPLETE:
+		cdrop := (*C.SDL_DropEvent)(cast(event))
+		drop := DropEvent{
+			Type:      uint32(cdrop._type),
+			Timestamp: uint32(cdrop.timestamp),
+			WindowID:  uint32(cdrop.windowID),
+		}
+		if cdrop.file != nil {
+			drop.File = C.GoString(cdrop.file)
+			// Clear the pointer so that decoding the same event
+			// twice can not free the string twice.
+			C.SDL_free(unsafe.Pointer(cdrop.file))
+			cdrop.file = nil
+		}
+		return drop
+
+	case RENDER_TARGETS_RESET, RENDER_DEVICE_RESET:
+		return *(*CommonEvent)(cast(event))
+	}
 
-	case USEREVENT:
+	if event.Type >= USEREVENT && event.Type < LASTEVENT {
 		return *(*UserEvent)(cast(event))
-
 	}
 
-	return nil
+	return *(*CommonEvent)(cast(event))
 }
 
+func (e CommonEvent) GetType() uint32           { return e.Type }
+func (e CommonEvent) GetTimestamp() uint32      { return e.Timestamp }
+func (e QuitEvent) GetType() uint32             { return e.Type }
+func (e QuitEvent) GetTimestamp() uint32        { return e.Timestamp }
+func (e OSEvent) GetType() uint32               { return e.Type }
+func (e OSEvent) GetTimestamp() uint32          { return e.Timestamp }
+func (e WindowEvent) GetType() uint32           { return e.Type }
+func (e WindowEvent) GetTimestamp() uint32      { return e.Timestamp }
+func (e SysWMEvent) GetType() uint32            { return e.Type }
+func (e SysWMEvent) GetTimestamp() uint32       { return e.Timestamp }
+func (e KeyboardEvent) GetType() uint32         { return e.Type }
+func (e KeyboardEvent) GetTimestamp() uint32    { return e.Timestamp }
+func (e TextEditingEvent) GetType() uint32      { return e.Type }
+func (e TextEditingEvent) GetTimestamp() uint32 { return e.Timestamp }
+func (e TextInputEvent) GetType() uint32        { return e.Type }
+func (e TextInputEvent) GetTimestamp() uint32   { return e.Timestamp }
+func (e MouseMotionEvent) GetType() uint32      { return e.Type }
+func (e MouseMotionEvent) GetTimestamp() uint32 { return e.Timestamp }
+func (e MouseButtonEvent) GetType() uint32      { return e.Type }
+func (e MouseButtonEvent) GetTimestamp() uint32 { return e.Timestamp }
+func (e MouseWheelEvent) GetType() uint32       { return e.Type }
+func (e MouseWheelEvent) GetTimestamp() uint32  { return e.Timestamp }
+func (e JoyAxisEvent) GetType() uint32          { return e.Type }
+func (e JoyAxisEvent) GetTimestamp() uint32     { return e.Timestamp }
+func (e JoyBallEvent) GetType() uint32          { return e.Type }
+func (e JoyBallEvent) GetTimestamp() uint32     { return e.Timestamp }
+func (e JoyHatEvent) GetType() uint32           { return e.Type }
+func (e JoyHatEvent) GetTimestamp() uint32      { return e.Timestamp }
+func (e JoyButtonEvent) GetType() uint32        { return e.Type }
+func (e JoyButtonEvent) GetTimestamp() uint32   { return e.Timestamp }
+func (e JoyDeviceEvent) GetType() uint32        { return e.Type }
+func (e JoyDeviceEvent) GetTimestamp() uint32   { return e.Timestamp }
+
+func (e ControllerAxisEvent) GetType() uint32        { return e.Type }
+func (e ControllerAxisEvent) GetTimestamp() uint32   { return e.Timestamp }
+func (e ControllerButtonEvent) GetType() uint32      { return e.Type }
+func (e ControllerButtonEvent) GetTimestamp() uint32 { return e.Timestamp }
+func (e ControllerDeviceEvent) GetType() uint32      { return e.Type }
+func (e ControllerDeviceEvent) GetTimestamp() uint32 { return e.Timestamp }
+func (e AudioDeviceEvent) GetType() uint32           { return e.Type }
+func (e AudioDeviceEvent) GetTimestamp() uint32      { return e.Timestamp }
+func (e TouchFingerEvent) GetType() uint32           { return e.Type }
+func (e TouchFingerEvent) GetTimestamp() uint32      { return e.Timestamp }
+func (e MultiGestureEvent) GetType() uint32          { return e.Type }
+func (e MultiGestureEvent) GetTimestamp() uint32     { return e.Timestamp }
+func (e DollarGestureEvent) GetType() uint32         { return e.Type }
+func (e DollarGestureEvent) GetTimestamp() uint32    { return e.Timestamp }
+func (e DropEvent) GetType() uint32                  { return e.Type }
+func (e DropEvent) GetTimestamp() uint32             { return e.Timestamp }
+func (e UserEvent) GetType() uint32                  { return e.Type }
+func (e UserEvent) GetTimestamp() uint32             { return e.Timestamp }
+
 func EventState(event uint, state int) int {
 	ret := C.SDL_EventState(C.Uint32(event), C.int(state))
 
@@ -163,3 +312,181 @@ func EventState(event uint, state int) int {
 func GetEventState(event uint) int {
 	return EventState(event, QUERY)
 }
+
+// Add an event to the event queue. Returns false if the event was
+// filtered out by the event filter.
+func PushEvent(event *Event) (bool, error) {
+	ret := int(C.SDL_PushEvent((*C.SDL_Event)(cast(event))))
+	if ret < 0 {
+		return false, NewSDLError()
+	}
+	return ret == 1, nil
+}
+
+// Check the event queue for messages and optionally return them.
+//
+// If action is ADDEVENT, all of events is added to the back of the
+// event queue. If action is PEEKEVENT, up to len(events) events at the
+// front of the event queue, within the specified minimum and maximum
+// type, will be copied into events and will not be removed from the
+// queue. If action is GETEVENT, the events are copied and removed from
+// the queue. Returns the number of events added or retrieved.
+func PeepEvents(events []Event, action int, minType, maxType uint32) (int, error) {
+	var cevents *C.SDL_Event
+	if len(events) > 0 {
+		cevents = (*C.SDL_Event)(cast(&events[0]))
+	}
+	ret := int(C.SDL_PeepEvents(cevents, C.int(len(events)), C.SDL_eventaction(action),
+		C.Uint32(minType), C.Uint32(maxType)))
+	if ret < 0 {
+		return 0, NewSDLError()
+	}
+	return ret, nil
+}
+
+// Clears events of the given type from the event queue.
+func FlushEvent(eventType uint32) {
+	C.SDL_FlushEvent(C.Uint32(eventType))
+}
+
+// Clears events in the range [minType, maxType] from the event queue.
+func FlushEvents(minType, maxType uint32) {
+	C.SDL_FlushEvents(C.Uint32(minType), C.Uint32(maxType))
+}
+
+// Checks whether an event of the given type is in the event queue.
+func HasEvent(eventType uint32) bool {
+	return C.SDL_HasEvent(C.Uint32(eventType)) == C.SDL_TRUE
+}
+
+// Allocates a set of user-defined events, and returns the beginning
+// event number for that set of events. If there are not enough
+// user-defined events left, this function returns 0xFFFFFFFF.
+func RegisterEvents(numevents int) uint32 {
+	return uint32(C.SDL_RegisterEvents(C.int(numevents)))
+}
+
+// =========================
+// User events with payloads
+// =========================
+
+// Pushes a user event of the given type (usually obtained from
+// RegisterEvents) carrying an arbitrary Go value. It is safe to call
+// from any goroutine. The payload is kept in a handle table until the
+// receiver calls Release on the event, so that it is neither collected
+// nor handed to C as a Go pointer.
+func PushUserEvent(eventType uint32, code int32, payload interface{}) error {
+	var event Event
+	user := (*UserEvent)(cast(&event))
+	user.Type = eventType
+	user.Code = code
+	if payload != nil {
+		user.Data1 = handles.put(payload)
+	}
+	ok, err := PushEvent(&event)
+	if !ok && user.Data1 != 0 {
+		handles.delete(user.Data1)
+	}
+	if err == nil && !ok {
+		err = &SDLError{"event was filtered"}
+	}
+	return err
+}
+
+// Returns the Go value pushed with PushUserEvent, or nil if the event
+// carries none (or was already released).
+func (e *UserEvent) Payload() interface{} {
+	if e.Data1 == 0 {
+		return nil
+	}
+	return handles.get(e.Data1)
+}
+
+// Releases the payload pushed with PushUserEvent. Every user event
+// carrying a payload must be released exactly once by its final
+// receiver, or the payload is leaked.
+func (e *UserEvent) Release() {
+	if e.Data1 != 0 {
+		handles.delete(e.Data1)
+		e.Data1 = 0
+	}
+}
+
+// ======================
+// Event filters, watches
+// ======================
+
+// An EventFilter is called with each event as it is added to the
+// queue. Returning false drops the event (return values of watches
+// are ignored). Filters may run on any thread, and the event is only
+// valid for the duration of the call.
+//
+// Filters and watches see the event before it is queued, so they must
+// not call Get on DROPFILE and DROPTEXT events: that frees the file
+// name, and the receiver of the queued event would get an empty File.
+// Check event.Type instead.
+type EventFilter func(event *Event) bool
+
+// Identifies a watch added with AddEventWatch.
+type EventWatch uintptr
+
+var (
+	eventFilterMu     sync.Mutex
+	eventFilterHandle uintptr
+)
+
+//export go_sdl2_event_filter
+func go_sdl2_event_filter(userdata C.uintptr_t, event *C.SDL_Event) C.int {
+	filter, ok := handles.get(uintptr(userdata)).(EventFilter)
+	if !ok {
+		return 1
+	}
+	return C.int(bool2int(filter((*Event)(unsafe.Pointer(event)))))
+}
+
+// Sets up a filter to process all events before they change internal
+// state and are posted to the internal event queue. Passing nil removes
+// the current filter.
+func SetEventFilter(filter EventFilter) {
+	var h uintptr
+	if filter != nil {
+		h = handles.put(filter)
+	}
+
+	eventFilterMu.Lock()
+	defer eventFilterMu.Unlock()
+	C.go_sdl2_set_event_filter(C.uintptr_t(h))
+	if eventFilterHandle != 0 {
+		handles.delete(eventFilterHandle)
+	}
+	eventFilterHandle = h
+}
+
+// Returns the current event filter, or nil if none is set.
+func GetEventFilter() EventFilter {
+	eventFilterMu.Lock()
+	defer eventFilterMu.Unlock()
+	filter, _ := handles.get(eventFilterHandle).(EventFilter)
+	return filter
+}
+
+// Add a function which is called when an event is added to the queue.
+func AddEventWatch(filter EventFilter) EventWatch {
+	h := handles.put(filter)
+	C.go_sdl2_add_event_watch(C.uintptr_t(h))
+	return EventWatch(h)
+}
+
+// Remove an event watch added with AddEventWatch.
+func DelEventWatch(watch EventWatch) {
+	C.go_sdl2_del_event_watch(C.uintptr_t(watch))
+	handles.delete(uintptr(watch))
+}
+
+// Run the filter on the current event queue, removing any events for
+// which the filter returns false.
+func FilterEvents(filter EventFilter) {
+	h := handles.put(filter)
+	C.go_sdl2_filter_events(C.uintptr_t(h))
+	handles.delete(h)
+}
@@ -0,0 +1,242 @@
+package sdl
+
+/*
+  SDL Go Wrapper
+
+  Simple DirectMedia Layer
+  Copyright (C) 1997-2013 Sam Lantinga <slouken@libsdl.org>
+
+  This software is provided 'as-is', without any express or implied
+  warranty.  In no event will the authors be held liable for any damages
+  arising from the use of this software.
+
+  Permission is granted to anyone to use this software for any purpose,
+  including commercial applications, and to alter it and redistribute it
+  freely, subject to the following restrictions:
+
+  1. The origin of this software must not be misrepresented; you must not
+     claim that you wrote the original software. If you use this software
+     in a product, an acknowledgment in the product documentation would be
+     appreciated but is not required.
+  2. Altered source versions must be plainly marked as such, and must not be
+     misrepresented as being the original software.
+  3. This notice may not be removed or altered from any source distribution.
+*/
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+import "unsafe"
+
+const (
+	// game controller axes
+
+	CONTROLLER_AXIS_INVALID      = C.SDL_CONTROLLER_AXIS_INVALID
+	CONTROLLER_AXIS_LEFTX        = C.SDL_CONTROLLER_AXIS_LEFTX
+	CONTROLLER_AXIS_LEFTY        = C.SDL_CONTROLLER_AXIS_LEFTY
+	CONTROLLER_AXIS_RIGHTX       = C.SDL_CONTROLLER_AXIS_RIGHTX
+	CONTROLLER_AXIS_RIGHTY       = C.SDL_CONTROLLER_AXIS_RIGHTY
+	CONTROLLER_AXIS_TRIGGERLEFT  = C.SDL_CONTROLLER_AXIS_TRIGGERLEFT
+	CONTROLLER_AXIS_TRIGGERRIGHT = C.SDL_CONTROLLER_AXIS_TRIGGERRIGHT
+	CONTROLLER_AXIS_MAX          = C.SDL_CONTROLLER_AXIS_MAX
+
+	// game controller buttons
+
+	CONTROLLER_BUTTON_INVALID       = C.SDL_CONTROLLER_BUTTON_INVALID
+	CONTROLLER_BUTTON_A             = C.SDL_CONTROLLER_BUTTON_A
+	CONTROLLER_BUTTON_B             = C.SDL_CONTROLLER_BUTTON_B
+	CONTROLLER_BUTTON_X             = C.SDL_CONTROLLER_BUTTON_X
+	CONTROLLER_BUTTON_Y             = C.SDL_CONTROLLER_BUTTON_Y
+	CONTROLLER_BUTTON_BACK          = C.SDL_CONTROLLER_BUTTON_BACK
+	CONTROLLER_BUTTON_GUIDE         = C.SDL_CONTROLLER_BUTTON_GUIDE
+	CONTROLLER_BUTTON_START         = C.SDL_CONTROLLER_BUTTON_START
+	CONTROLLER_BUTTON_LEFTSTICK     = C.SDL_CONTROLLER_BUTTON_LEFTSTICK
+	CONTROLLER_BUTTON_RIGHTSTICK    = C.SDL_CONTROLLER_BUTTON_RIGHTSTICK
+	CONTROLLER_BUTTON_LEFTSHOULDER  = C.SDL_CONTROLLER_BUTTON_LEFTSHOULDER
+	CONTROLLER_BUTTON_RIGHTSHOULDER = C.SDL_CONTROLLER_BUTTON_RIGHTSHOULDER
+	CONTROLLER_BUTTON_DPAD_UP       = C.SDL_CONTROLLER_BUTTON_DPAD_UP
+	CONTROLLER_BUTTON_DPAD_DOWN     = C.SDL_CONTROLLER_BUTTON_DPAD_DOWN
+	CONTROLLER_BUTTON_DPAD_LEFT     = C.SDL_CONTROLLER_BUTTON_DPAD_LEFT
+	CONTROLLER_BUTTON_DPAD_RIGHT    = C.SDL_CONTROLLER_BUTTON_DPAD_RIGHT
+	CONTROLLER_BUTTON_MAX           = C.SDL_CONTROLLER_BUTTON_MAX
+)
+
+// ===============
+// Game Controller
+// ===============
+
+type GameController struct {
+	cGameController *C.SDL_GameController
+}
+
+func wrapGameController(cGameController *C.SDL_GameController) *GameController {
+	var gc *GameController
+	if cGameController != nil {
+		var controller GameController
+		controller.cGameController = (*C.SDL_GameController)(unsafe.Pointer(cGameController))
+		gc = &controller
+	} else {
+		gc = nil
+	}
+	return gc
+}
+
+// Load a set of mappings from a file, filtered by the current platform.
+// Returns the number of mappings added.
+func GameControllerAddMappingsFromFile(file string) (int, error) {
+	rw := RWFromFile(file, "rb")
+	if rw == nil {
+		return 0, NewSDLError()
+	}
+	return GameControllerAddMappingsFromRW(rw, true)
+}
+
+// Load a set of mappings from a seekable SDL data stream, filtered by
+// the current platform. If freerw is true, the stream is closed
+// afterwards even on error. Returns the number of mappings added.
+func GameControllerAddMappingsFromRW(rw *RWops, freerw bool) (int, error) {
+	ret := int(C.SDL_GameControllerAddMappingsFromRW(rw.cRWops, C.int(bool2int(freerw))))
+	if freerw {
+		rw.cRWops = nil
+		rw.mem = nil
+	}
+	if ret < 0 {
+		return 0, NewSDLError()
+	}
+	return ret, nil
+}
+
+// Add or update an existing mapping configuration. Returns true if a
+// new mapping was added and false if an existing one was updated.
+func GameControllerAddMapping(mapping string) (bool, error) {
+	cmapping := C.CString(mapping)
+	defer C.free(unsafe.Pointer(cmapping))
+	ret := int(C.SDL_GameControllerAddMapping(cmapping))
+	if ret < 0 {
+		return false, NewSDLError()
+	}
+	return ret == 1, nil
+}
+
+// Get the mapping string for a joystick GUID (as returned by
+// Joystick.GetGUID), or an empty string if there is no mapping.
+func GameControllerMappingForGUID(guid string) string {
+	cguid := C.CString(guid)
+	defer C.free(unsafe.Pointer(cguid))
+	cmapping := C.SDL_GameControllerMappingForGUID(C.SDL_JoystickGetGUIDFromString(cguid))
+	if cmapping == nil {
+		return ""
+	}
+	defer C.SDL_free(unsafe.Pointer(cmapping))
+	return C.GoString(cmapping)
+}
+
+// Is the joystick on this index supported by the game controller
+// interface?
+func IsGameController(deviceIndex int) bool {
+	return C.SDL_IsGameController(C.int(deviceIndex)) == C.SDL_TRUE
+}
+
+// Get the implementation dependent name of a game controller. This can
+// be called before any controllers are opened. If no name can be
+// found, this function returns an empty string.
+func GameControllerNameForIndex(deviceIndex int) string {
+	return C.GoString(C.SDL_GameControllerNameForIndex(C.int(deviceIndex)))
+}
+
+// Open a game controller for use. The index passed as an argument
+// refers to the N'th game controller on the system. This index is the
+// value which will identify this controller in future controller
+// events. Returns nil if an error occurred.
+func GameControllerOpen(deviceIndex int) *GameController {
+	return wrapGameController(C.SDL_GameControllerOpen(C.int(deviceIndex)))
+}
+
+// Get the already opened game controller associated with a joystick
+// instance id, as found in the Which field of controller events.
+func GameControllerFromInstanceID(joyid int32) *GameController {
+	return wrapGameController(C.SDL_GameControllerFromInstanceID(C.SDL_JoystickID(joyid)))
+}
+
+// Enable/disable controller event polling. If controller events are
+// disabled, you must call GameControllerUpdate() yourself and check the
+// state of the controller when you want controller information. The
+// state can be one of QUERY, ENABLE or IGNORE.
+func GameControllerEventState(state int) int {
+	return int(C.SDL_GameControllerEventState(C.int(state)))
+}
+
+// Update the current state of the open game controllers. This is called
+// automatically by the event loop if any game controller events are
+// enabled.
+func GameControllerUpdate() {
+	C.SDL_GameControllerUpdate()
+}
+
+// Convert a string into a CONTROLLER_AXIS_* value, e.g. "leftx".
+func GameControllerGetAxisFromString(name string) int {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return int(C.SDL_GameControllerGetAxisFromString(cname))
+}
+
+// Convert a CONTROLLER_AXIS_* value into its string representation.
+func GameControllerGetStringForAxis(axis int) string {
+	return C.GoString(C.SDL_GameControllerGetStringForAxis(C.SDL_GameControllerAxis(axis)))
+}
+
+// Convert a string into a CONTROLLER_BUTTON_* value, e.g. "start".
+func GameControllerGetButtonFromString(name string) int {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return int(C.SDL_GameControllerGetButtonFromString(cname))
+}
+
+// Convert a CONTROLLER_BUTTON_* value into its string representation.
+func GameControllerGetStringForButton(button int) string {
+	return C.GoString(C.SDL_GameControllerGetStringForButton(C.SDL_GameControllerButton(button)))
+}
+
+// Close a game controller previously opened with GameControllerOpen()
+func (gc *GameController) Close() {
+	C.SDL_GameControllerClose(gc.cGameController)
+}
+
+// Return the name for this currently opened controller
+func (gc *GameController) GetName() string {
+	return C.GoString(C.SDL_GameControllerName(gc.cGameController))
+}
+
+// Returns true if the controller has been opened and currently
+// connected, or false if it has not.
+func (gc *GameController) GetAttached() bool {
+	return C.SDL_GameControllerGetAttached(gc.cGameController) == C.SDL_TRUE
+}
+
+// Get the underlying joystick object used by a controller
+func (gc *GameController) GetJoystick() *Joystick {
+	return wrapJoystick(C.SDL_GameControllerGetJoystick(gc.cGameController))
+}
+
+// Get the current mapping of the game controller, or an empty string
+// if there is none.
+func (gc *GameController) Mapping() string {
+	cmapping := C.SDL_GameControllerMapping(gc.cGameController)
+	if cmapping == nil {
+		return ""
+	}
+	defer C.SDL_free(unsafe.Pointer(cmapping))
+	return C.GoString(cmapping)
+}
+
+// Get the current state of an axis control on a game controller. The
+// state is a value ranging from -32768 to 32767; triggers range from 0
+// to 32767.
+func (gc *GameController) GetAxis(axis int) int16 {
+	return int16(C.SDL_GameControllerGetAxis(gc.cGameController, C.SDL_GameControllerAxis(axis)))
+}
+
+// Get the current state of a button on a game controller.
+func (gc *GameController) GetButton(button int) uint8 {
+	return uint8(C.SDL_GameControllerGetButton(gc.cGameController, C.SDL_GameControllerButton(button)))
+}
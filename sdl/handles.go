@@ -0,0 +1,61 @@
+package sdl
+
+/*
+  SDL Go Wrapper
+
+  This software is provided 'as-is', without any express or implied
+  warranty.  In no event will the authors be held liable for any damages
+  arising from the use of this software.
+
+  Permission is granted to anyone to use this software for any purpose,
+  including commercial applications, and to alter it and redistribute it
+  freely, subject to the following restrictions:
+
+  1. The origin of this software must not be misrepresented; you must not
+     claim that you wrote the original software. If you use this software
+     in a product, an acknowledgment in the product documentation would be
+     appreciated but is not required.
+  2. Altered source versions must be plainly marked as such, and must not be
+     misrepresented as being the original software.
+  3. This notice may not be removed or altered from any source distribution.
+*/
+
+import "sync"
+
+// Go values that C code hands back to us (callback userdata, event
+// payloads) can not be passed to C as Go pointers, since C may keep
+// them around after the call returns. Instead they are stored in this
+// table and C only ever sees the integer handle. Handle 0 is never
+// allocated and means "nothing".
+type handleTable struct {
+	mu      sync.Mutex
+	next    uintptr
+	entries map[uintptr]interface{}
+}
+
+var handles = handleTable{entries: make(map[uintptr]interface{})}
+
+func (t *handleTable) put(v interface{}) uintptr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for {
+		t.next++
+		if _, used := t.entries[t.next]; t.next != 0 && !used {
+			break
+		}
+	}
+	t.entries[t.next] = v
+	return t.next
+}
+
+func (t *handleTable) get(h uintptr) interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.entries[h]
+}
+
+func (t *handleTable) delete(h uintptr) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, h)
+}
@@ -0,0 +1,309 @@
+package sdl
+
+/*
+  SDL Go Wrapper
+
+  Simple DirectMedia Layer
+  Copyright (C) 1997-2013 Sam Lantinga <slouken@libsdl.org>
+
+  This software is provided 'as-is', without any express or implied
+  warranty.  In no event will the authors be held liable for any damages
+  arising from the use of this software.
+
+  Permission is granted to anyone to use this software for any purpose,
+  including commercial applications, and to alter it and redistribute it
+  freely, subject to the following restrictions:
+
+  1. The origin of this software must not be misrepresented; you must not
+     claim that you wrote the original software. If you use this software
+     in a product, an acknowledgment in the product documentation would be
+     appreciated but is not required.
+  2. Altered source versions must be plainly marked as such, and must not be
+     misrepresented as being the original software.
+  3. This notice may not be removed or altered from any source distribution.
+*/
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"unsafe"
+)
+
+// The byte order SDL uses for pixel values wider than a byte.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// ===============
+// Colors
+// ===============
+
+// ColorModel converts any color.Color to an sdl.Color. Like
+// image/color's NRGBA, sdl.Color is not alpha-premultiplied.
+var ColorModel color.Model = color.ModelFunc(colorModel)
+
+func colorModel(c color.Color) color.Color {
+	if c, ok := c.(Color); ok {
+		return c
+	}
+	r, g, b, a := c.RGBA()
+	if a == 0xffff {
+		return Color{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 0xff}
+	}
+	if a == 0 {
+		return Color{0, 0, 0, 0}
+	}
+	// Since Color.RGBA returns an alpha-premultiplied color, we undo that.
+	r = (r * 0xffff) / a
+	g = (g * 0xffff) / a
+	b = (b * 0xffff) / a
+	return Color{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+}
+
+// RGBA implements color.Color. It returns the alpha-premultiplied red,
+// green, blue and alpha values of the color.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	r = uint32(c.R)
+	r |= r << 8
+	r *= uint32(c.A)
+	r /= 0xff
+	g = uint32(c.G)
+	g |= g << 8
+	g *= uint32(c.A)
+	g /= 0xff
+	b = uint32(c.B)
+	b |= b << 8
+	b *= uint32(c.A)
+	b /= 0xff
+	a = uint32(c.A)
+	a |= a << 8
+	return
+}
+
+// Get the colors of a palette as a slice backed by the palette itself.
+func (p *Palette) colors() []Color {
+	n := int(p.Ncolors)
+	return (*[1 << 16]Color)(unsafe.Pointer(p.Colors))[:n:n]
+}
+
+// ===============
+// Surface as image.Image
+// ===============
+
+// Bounds implements image.Image.
+func (s *Surface) Bounds() image.Rectangle {
+	return image.Rect(0, 0, int(s.W), int(s.H))
+}
+
+// ColorModel implements image.Image. Paletted surfaces report their
+// palette, all others sdl.ColorModel.
+func (s *Surface) ColorModel() color.Model {
+	if s.Format.Palette != nil {
+		colors := s.Format.Palette.colors()
+		p := make(color.Palette, len(colors))
+		for i, c := range colors {
+			p[i] = c
+		}
+		return p
+	}
+	return ColorModel
+}
+
+// At implements image.Image. It decodes the pixel at (x, y) using the
+// masks, shifts and losses of the surface format, or its palette.
+// Pixels outside the surface are transparent black. RLE accelerated
+// surfaces must be locked while accessing their pixels.
+func (s *Surface) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(s.Bounds())) {
+		return Color{}
+	}
+	f := s.Format
+	pixel := s.getPixel(x, y)
+	if f.Palette != nil {
+		colors := f.Palette.colors()
+		if int(pixel) < len(colors) {
+			return colors[pixel]
+		}
+		return Color{}
+	}
+	c := Color{
+		R: expandComponent(pixel, f.Rmask, f.Rshift, f.Rloss),
+		G: expandComponent(pixel, f.Gmask, f.Gshift, f.Gloss),
+		B: expandComponent(pixel, f.Bmask, f.Bshift, f.Bloss),
+		A: 0xff,
+	}
+	if f.Amask != 0 {
+		c.A = expandComponent(pixel, f.Amask, f.Ashift, f.Aloss)
+	}
+	return c
+}
+
+// Set implements draw.Image. The color is encoded into the surface
+// format; paletted surfaces store the closest palette entry. Pixels
+// outside the surface are ignored.
+func (s *Surface) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(s.Bounds())) {
+		return
+	}
+	f := s.Format
+	if f.Palette != nil {
+		s.setPixel(x, y, uint32(paletteIndex(f.Palette.colors(), c)))
+		return
+	}
+	sc := ColorModel.Convert(c).(Color)
+	pixel := uint32(sc.R)>>f.Rloss<<f.Rshift&f.Rmask |
+		uint32(sc.G)>>f.Gloss<<f.Gshift&f.Gmask |
+		uint32(sc.B)>>f.Bloss<<f.Bshift&f.Bmask |
+		uint32(sc.A)>>f.Aloss<<f.Ashift&f.Amask
+	s.setPixel(x, y, pixel)
+}
+
+// Returns the index of the palette color closest to c, like
+// color.Palette.Index, without building a color.Palette first.
+func paletteIndex(colors []Color, c color.Color) int {
+	cr, cg, cb, ca := c.RGBA()
+	ret, bestSum := 0, uint32(1<<32-1)
+	for i, v := range colors {
+		vr, vg, vb, va := v.RGBA()
+		sum := sqDiff(cr, vr) + sqDiff(cg, vg) + sqDiff(cb, vb) + sqDiff(ca, va)
+		if sum < bestSum {
+			if sum == 0 {
+				return i
+			}
+			ret, bestSum = i, sum
+		}
+	}
+	return ret
+}
+
+// Returns the squared difference of two 16-bit color components,
+// divided by 4 so that the sum of four fits in a uint32.
+func sqDiff(x, y uint32) uint32 {
+	d := x - y
+	return (d * d) >> 2
+}
+
+// Scale a component of at most 8 bits up to the full 8 bit range by
+// repeating its bits, like SDL_GetRGBA does.
+func expandComponent(pixel, mask uint32, shift, loss uint8) uint8 {
+	if mask == 0 {
+		return 0
+	}
+	v := (pixel & mask) >> shift << loss
+	for bits := 8 - uint(loss); bits < 8; bits += 8 - uint(loss) {
+		v |= v >> bits
+	}
+	return uint8(v)
+}
+
+// The bytes of the surface pixels, viewed as a slice. SDL does not
+// allocate pixels for empty surfaces, so this may be nil.
+func (s *Surface) pixelBytes() []byte {
+	if s.Pixels == nil {
+		return nil
+	}
+	n := int(s.Pitch) * int(s.H)
+	return (*[1 << 30]byte)(s.Pixels)[:n:n]
+}
+
+// Pixel order of indexed formats with less than 8 bits per pixel.
+const bitmapOrder4321 = 1
+
+func (s *Surface) getPixel(x, y int) uint32 {
+	row := s.pixelBytes()[y*int(s.Pitch):]
+	f := s.Format
+	switch f.BytesPerPixel {
+	case 1:
+		if f.BitsPerPixel < 8 {
+			bpp := uint(f.BitsPerPixel)
+			b := row[x*int(bpp)/8]
+			pos := uint(x) * bpp % 8
+			if (f.Format>>20)&0x0F != bitmapOrder4321 {
+				pos = 8 - bpp - pos
+			}
+			return uint32(b>>pos) & (1<<bpp - 1)
+		}
+		return uint32(row[x])
+	case 2:
+		return uint32(nativeEndian.Uint16(row[x*2:]))
+	case 3:
+		p := row[x*3 : x*3+3]
+		if nativeEndian == binary.LittleEndian {
+			return uint32(p[0]) | uint32(p[1])<<8 | uint32(p[2])<<16
+		}
+		return uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+	default:
+		return nativeEndian.Uint32(row[x*4:])
+	}
+}
+
+func (s *Surface) setPixel(x, y int, pixel uint32) {
+	row := s.pixelBytes()[y*int(s.Pitch):]
+	f := s.Format
+	switch f.BytesPerPixel {
+	case 1:
+		if f.BitsPerPixel < 8 {
+			bpp := uint(f.BitsPerPixel)
+			b := &row[x*int(bpp)/8]
+			pos := uint(x) * bpp % 8
+			if (f.Format>>20)&0x0F != bitmapOrder4321 {
+				pos = 8 - bpp - pos
+			}
+			mask := byte(1<<bpp-1) << pos
+			*b = *b&^mask | byte(pixel)<<pos&mask
+			return
+		}
+		row[x] = byte(pixel)
+	case 2:
+		nativeEndian.PutUint16(row[x*2:], uint16(pixel))
+	case 3:
+		p := row[x*3 : x*3+3]
+		if nativeEndian == binary.LittleEndian {
+			p[0], p[1], p[2] = byte(pixel), byte(pixel>>8), byte(pixel>>16)
+		} else {
+			p[0], p[1], p[2] = byte(pixel>>16), byte(pixel>>8), byte(pixel)
+		}
+	default:
+		nativeEndian.PutUint32(row[x*4:], pixel)
+	}
+}
+
+// Creates a new 32-bit RGBA Surface holding a copy of img, so images
+// from Go's decoders can be used with SDL. The surface origin is the
+// top-left corner of the image bounds.
+func NewSurfaceFromImage(img image.Image) (*Surface, error) {
+	b := img.Bounds()
+	var rmask, gmask, bmask, amask uint32 = 0x000000ff, 0x0000ff00, 0x00ff0000, 0xff000000
+	if nativeEndian == binary.BigEndian {
+		rmask, gmask, bmask, amask = 0xff000000, 0x00ff0000, 0x0000ff00, 0x000000ff
+	}
+	s := CreateRGBSurface(0, b.Dx(), b.Dy(), 32, rmask, gmask, bmask, amask)
+	if s == nil {
+		return nil, NewSDLError()
+	}
+
+	// The surface stores R, G, B, A bytes in memory order, just like
+	// image.NRGBA, so those rows can be copied directly.
+	pixels := s.pixelBytes()
+	if src, ok := img.(*image.NRGBA); ok {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			i := src.PixOffset(b.Min.X, y)
+			copy(pixels[(y-b.Min.Y)*int(s.Pitch):], src.Pix[i:i+b.Dx()*4])
+		}
+		return s, nil
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := pixels[(y-b.Min.Y)*int(s.Pitch):]
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := ColorModel.Convert(img.At(x, y)).(Color)
+			i := (x - b.Min.X) * 4
+			row[i], row[i+1], row[i+2], row[i+3] = c.R, c.G, c.B, c.A
+		}
+	}
+	return s, nil
+}
@@ -218,6 +218,28 @@ func JoystickEventState(state int) int {
 	return result
 }
 
+// Get the instance id of an opened joystick. This is the value found
+// in the Which field of joystick and controller events.
+func (joystick *Joystick) InstanceID() int32 {
+	return int32(C.SDL_JoystickInstanceID(joystick.cJoystick))
+}
+
+// Return the GUID of an opened joystick as an ASCII string, suitable
+// for use with GameControllerMappingForGUID.
+func (joystick *Joystick) GetGUID() string {
+	var buf [33]C.char
+	C.SDL_JoystickGetGUIDString(C.SDL_JoystickGetGUID(joystick.cJoystick), &buf[0], C.int(len(buf)))
+	return C.GoString(&buf[0])
+}
+
+// Return the GUID of the joystick at the given device index as an
+// ASCII string. This can be called before any joysticks are opened.
+func JoystickGetDeviceGUID(deviceIndex int) string {
+	var buf [33]C.char
+	C.SDL_JoystickGetGUIDString(C.SDL_JoystickGetDeviceGUID(C.int(deviceIndex)), &buf[0], C.int(len(buf)))
+	return C.GoString(&buf[0])
+}
+
 // Close a joystick previously opened with SDL_JoystickOpen()
 func (joystick *Joystick) Close() {
 	C.SDL_JoystickClose(joystick.cJoystick)
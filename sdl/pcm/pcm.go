@@ -0,0 +1,287 @@
+/*
+Package pcm streams raw PCM audio between Go readers and writers and
+SDL audio devices.
+
+A Player reads PCM from an io.Reader on its own goroutine and buffers it
+in a lock-free ring that the device callback drains, so the audio
+thread never blocks on Go code. A Recorder does the reverse for capture
+devices, writing everything captured to an io.Writer. SDL must have
+been initialized with sdl.INIT_AUDIO.
+
+	p, err := pcm.NewPlayer("", sdl.AudioSpec{Freq: 44100, Format: sdl.AUDIO_S16SYS, Channels: 2, Samples: 1024}, src)
+	if err != nil {
+		...
+	}
+	p.Resume()
+	<-p.Done()
+	p.Close()
+*/
+package pcm
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/krig/Go-SDL2/sdl"
+)
+
+// The ring buffers hold this many device buffers worth of audio.
+const bufferCount = 4
+
+// Returns the ring buffer size to use for an obtained spec.
+func bufferSize(spec *sdl.AudioSpec) int {
+	size := int(spec.Size) * bufferCount
+	if size < 4096 {
+		size = 4096
+	}
+	return size
+}
+
+// Reports a wake-up without ever blocking the audio thread.
+func notify(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// A Player plays PCM audio read from an io.Reader on an audio device.
+type Player struct {
+	underruns uint64 // Accessed atomically; kept first for 64-bit alignment
+	eof       uint32 // Set once everything read from src is in the ring
+
+	dev  sdl.AudioDeviceID
+	spec sdl.AudioSpec
+	ring *ring
+	src  io.Reader
+
+	wake      chan struct{} // Signalled by the callback after reading
+	quit      chan struct{}
+	done      chan struct{}
+	doneOnce  sync.Once
+	closeOnce sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// Opens an audio device for playing the PCM data in src, which must be
+// in the format described by spec. An empty device name opens the
+// default device. The player starts out paused; call Resume to start
+// playback.
+func NewPlayer(device string, spec sdl.AudioSpec, src io.Reader) (*Player, error) {
+	p := &Player{
+		src:  src,
+		wake: make(chan struct{}, 1),
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	dev, err := sdl.OpenAudioDevice(device, false, &spec, &p.spec, 0, p.fill)
+	if err != nil {
+		return nil, err
+	}
+	p.dev = dev
+	p.ring = newRing(bufferSize(&p.spec))
+	go p.feed()
+	return p, nil
+}
+
+// Reads src into the ring until it ends or the player is closed.
+func (p *Player) feed() {
+	buf := make([]byte, len(p.ring.buf)/bufferCount)
+	for {
+		n, err := p.src.Read(buf)
+		for data := buf[:n]; len(data) > 0; {
+			data = data[p.ring.write(data):]
+			if len(data) > 0 {
+				select {
+				case <-p.wake:
+				case <-p.quit:
+					return
+				}
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				p.setErr(err)
+			}
+			atomic.StoreUint32(&p.eof, 1)
+			return
+		}
+	}
+}
+
+// The device callback.
+func (p *Player) fill(stream []byte) {
+	// Check for the end before reading, since only then is it certain
+	// that an empty ring means everything has been played.
+	eof := atomic.LoadUint32(&p.eof) != 0
+	n := p.ring.read(stream)
+	if n < len(stream) {
+		for i := n; i < len(stream); i++ {
+			stream[i] = p.spec.Silence
+		}
+		if eof {
+			p.doneOnce.Do(func() { close(p.done) })
+		} else {
+			atomic.AddUint64(&p.underruns, 1)
+		}
+	}
+	notify(p.wake)
+}
+
+func (p *Player) setErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// The spec obtained from the device.
+func (p *Player) Spec() sdl.AudioSpec {
+	return p.spec
+}
+
+// Returns a channel that is closed once src has ended and all of it
+// has been played.
+func (p *Player) Done() <-chan struct{} {
+	return p.done
+}
+
+// The number of times the device needed more audio than had been read
+// from src, and silence was played instead.
+func (p *Player) Underruns() uint64 {
+	return atomic.LoadUint64(&p.underruns)
+}
+
+// Returns the first error returned by src other than io.EOF, if any.
+func (p *Player) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Pauses playback. Audio keeps being buffered up to the ring size.
+func (p *Player) Pause() {
+	sdl.PauseAudioDevice(p.dev, true)
+}
+
+// Starts or resumes playback.
+func (p *Player) Resume() {
+	sdl.PauseAudioDevice(p.dev, false)
+}
+
+// Stops playback and closes the device. The reading goroutine exits
+// once its current Read returns. Returns the same as Err.
+func (p *Player) Close() error {
+	p.closeOnce.Do(func() {
+		sdl.CloseAudioDevice(p.dev)
+		close(p.quit)
+	})
+	return p.Err()
+}
+
+// A Recorder writes PCM audio captured by an audio device to an
+// io.Writer.
+type Recorder struct {
+	overruns uint64 // Accessed atomically; kept first for 64-bit alignment
+
+	dev  sdl.AudioDeviceID
+	spec sdl.AudioSpec
+	ring *ring
+	dst  io.Writer
+
+	wake      chan struct{} // Signalled by the callback after writing
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error // Only touched by drain until done is closed
+}
+
+// Opens a capture device, writing everything it records to dst in the
+// format described by spec. An empty device name opens the default
+// capture device. The recorder starts out paused; call Resume to start
+// recording.
+func NewRecorder(device string, spec sdl.AudioSpec, dst io.Writer) (*Recorder, error) {
+	r := &Recorder{
+		dst:  dst,
+		wake: make(chan struct{}, 1),
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	dev, err := sdl.OpenAudioDevice(device, true, &spec, &r.spec, 0, r.capture)
+	if err != nil {
+		return nil, err
+	}
+	r.dev = dev
+	r.ring = newRing(bufferSize(&r.spec))
+	go r.drain()
+	return r, nil
+}
+
+// The device callback.
+func (r *Recorder) capture(stream []byte) {
+	if r.ring.write(stream) < len(stream) {
+		atomic.AddUint64(&r.overruns, 1)
+	}
+	notify(r.wake)
+}
+
+// Writes the ring to dst until the recorder is closed.
+func (r *Recorder) drain() {
+	defer close(r.done)
+	buf := make([]byte, len(r.ring.buf)/bufferCount)
+	for {
+		n := r.ring.read(buf)
+		if n > 0 {
+			if r.err == nil {
+				_, r.err = r.dst.Write(buf[:n])
+			}
+			continue
+		}
+		select {
+		case <-r.wake:
+		case <-r.quit:
+			// The device is closed, so nothing is added anymore.
+			for n := r.ring.read(buf); n > 0 && r.err == nil; n = r.ring.read(buf) {
+				_, r.err = r.dst.Write(buf[:n])
+			}
+			return
+		}
+	}
+}
+
+// The spec obtained from the device.
+func (r *Recorder) Spec() sdl.AudioSpec {
+	return r.spec
+}
+
+// The number of times captured audio was dropped because dst could not
+// keep up.
+func (r *Recorder) Overruns() uint64 {
+	return atomic.LoadUint64(&r.overruns)
+}
+
+// Pauses recording.
+func (r *Recorder) Pause() {
+	sdl.PauseAudioDevice(r.dev, true)
+}
+
+// Starts or resumes recording.
+func (r *Recorder) Resume() {
+	sdl.PauseAudioDevice(r.dev, false)
+}
+
+// Stops recording, closes the device and waits until all captured
+// audio has been written to dst. Returns the first error returned by
+// dst, if any.
+func (r *Recorder) Close() error {
+	r.closeOnce.Do(func() {
+		sdl.CloseAudioDevice(r.dev)
+		close(r.quit)
+	})
+	<-r.done
+	return r.err
+}
@@ -0,0 +1,55 @@
+package pcm
+
+import "sync/atomic"
+
+// A ring is a single-producer, single-consumer byte queue. Only the
+// producer advances w and only the consumer advances r, so the audio
+// callback on one side never has to wait for a lock held by the
+// goroutine on the other side.
+type ring struct {
+	r, w uint64 // Total bytes read and written; kept first for 64-bit alignment
+	mask uint64
+	buf  []byte
+}
+
+// Creates a ring holding at least size bytes.
+func newRing(size int) *ring {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	return &ring{mask: uint64(n - 1), buf: make([]byte, n)}
+}
+
+// The number of bytes that can be read.
+func (q *ring) readable() int {
+	return int(atomic.LoadUint64(&q.w) - atomic.LoadUint64(&q.r))
+}
+
+// Writes as much of p as fits and returns the number of bytes written.
+// Must only be called by the producer.
+func (q *ring) write(p []byte) int {
+	w := q.w
+	free := len(q.buf) - int(w-atomic.LoadUint64(&q.r))
+	if len(p) > free {
+		p = p[:free]
+	}
+	n := copy(q.buf[w&q.mask:], p)
+	copy(q.buf, p[n:])
+	atomic.StoreUint64(&q.w, w+uint64(len(p)))
+	return len(p)
+}
+
+// Reads as much as is available into p and returns the number of bytes
+// read. Must only be called by the consumer.
+func (q *ring) read(p []byte) int {
+	r := q.r
+	avail := int(atomic.LoadUint64(&q.w) - r)
+	if len(p) > avail {
+		p = p[:avail]
+	}
+	n := copy(p, q.buf[r&q.mask:])
+	copy(p[n:], q.buf)
+	atomic.StoreUint64(&q.r, r+uint64(len(p)))
+	return len(p)
+}
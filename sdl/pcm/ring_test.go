@@ -0,0 +1,76 @@
+package pcm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRingEmpty(t *testing.T) {
+	q := newRing(8)
+	if n := q.readable(); n != 0 {
+		t.Errorf("readable() = %d, want 0", n)
+	}
+	p := make([]byte, 4)
+	if n := q.read(p); n != 0 {
+		t.Errorf("read() = %d from an empty ring, want 0", n)
+	}
+}
+
+func TestRingSize(t *testing.T) {
+	for _, size := range []int{1, 5, 8, 1000} {
+		q := newRing(size)
+		n := len(q.buf)
+		if n < size || n&(n-1) != 0 {
+			t.Errorf("newRing(%d) holds %d bytes, want a power of two of at least %d", size, n, size)
+		}
+	}
+}
+
+func TestRingFull(t *testing.T) {
+	q := newRing(8)
+	if n := q.write([]byte("0123456789")); n != 8 {
+		t.Errorf("write() = %d, want 8", n)
+	}
+	if n := q.write([]byte("x")); n != 0 {
+		t.Errorf("write() = %d to a full ring, want 0", n)
+	}
+	if n := q.readable(); n != 8 {
+		t.Errorf("readable() = %d, want 8", n)
+	}
+	p := make([]byte, 16)
+	if n := q.read(p); n != 8 || string(p[:n]) != "01234567" {
+		t.Errorf("read() = %d, %q, want 8, %q", n, p[:n], "01234567")
+	}
+}
+
+func TestRingWraparound(t *testing.T) {
+	q := newRing(8)
+	var written, read bytes.Buffer
+	next := byte(0)
+	p := make([]byte, 8)
+	// Chunk sizes that are not divisors of the ring size move the
+	// positions across the end of the buffer in every way.
+	for i := 0; i < 100; i++ {
+		chunk := make([]byte, 1+i%7)
+		for j := range chunk {
+			chunk[j] = next
+			next++
+		}
+		n := q.write(chunk)
+		written.Write(chunk[:n])
+		next -= byte(len(chunk) - n)
+
+		m := q.read(p[:1+i%5])
+		read.Write(p[:m])
+		if got, want := q.readable(), written.Len()-read.Len(); got != want {
+			t.Fatalf("readable() = %d after %d steps, want %d", got, i, want)
+		}
+	}
+	for q.readable() > 0 {
+		m := q.read(p)
+		read.Write(p[:m])
+	}
+	if !bytes.Equal(read.Bytes(), written.Bytes()) {
+		t.Errorf("read %v, want %v", read.Bytes(), written.Bytes())
+	}
+}
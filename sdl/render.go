@@ -26,7 +26,10 @@ package sdl
 // #cgo pkg-config: sdl2
 // #include <SDL2/SDL.h>
 import "C"
-import "unsafe"
+import (
+	"image"
+	"unsafe"
+)
 
 type Renderer struct {
 	cRenderer *C.SDL_Renderer
@@ -34,6 +37,30 @@ type Renderer struct {
 
 type Texture struct {
 	cTexture *C.SDL_Texture
+	locked   *LockedPixels // nil while unlocked
+}
+
+// Information on the capabilities of a render driver or context.
+type RendererInfo struct {
+	Name             string   // The name of the renderer
+	Flags            uint32   // Supported RENDERER_* flags
+	TextureFormats   []uint32 // The available texture formats
+	MaxTextureWidth  int      // The maximum texture width
+	MaxTextureHeight int      // The maximum texture height
+}
+
+func wrapRendererInfo(cinfo *C.SDL_RendererInfo) RendererInfo {
+	info := RendererInfo{
+		Name:             C.GoString(cinfo.name),
+		Flags:            uint32(cinfo.flags),
+		TextureFormats:   make([]uint32, int(cinfo.num_texture_formats)),
+		MaxTextureWidth:  int(cinfo.max_texture_width),
+		MaxTextureHeight: int(cinfo.max_texture_height),
+	}
+	for i := range info.TextureFormats {
+		info.TextureFormats[i] = uint32(cinfo.texture_formats[i])
+	}
+	return info
 }
 
 func wrapRenderer(cRenderer *C.SDL_Renderer) *Renderer {
@@ -70,6 +97,32 @@ func CreateRenderer(w *Window, index int, flags uint32) *Renderer {
 	return wrapRenderer(renderer)
 }
 
+// Get the number of 2D rendering drivers available for the current
+// display. The driver index passed to CreateRenderer ranges from 0 to
+// GetNumRenderDrivers()-1.
+func GetNumRenderDrivers() int {
+	return int(C.SDL_GetNumRenderDrivers())
+}
+
+// Get information about a specific 2D rendering driver for the current
+// display.
+func GetRenderDriverInfo(index int) (RendererInfo, error) {
+	var cinfo C.SDL_RendererInfo
+	if C.SDL_GetRenderDriverInfo(C.int(index), &cinfo) != 0 {
+		return RendererInfo{}, NewSDLError()
+	}
+	return wrapRendererInfo(&cinfo), nil
+}
+
+// Get information about a rendering context.
+func (r *Renderer) GetInfo() (RendererInfo, error) {
+	var cinfo C.SDL_RendererInfo
+	if C.SDL_GetRendererInfo(r.cRenderer, &cinfo) != 0 {
+		return RendererInfo{}, NewSDLError()
+	}
+	return wrapRendererInfo(&cinfo), nil
+}
+
 func (r *Renderer) Clear() {
 	C.SDL_RenderClear(r.cRenderer)
 }
@@ -100,6 +153,170 @@ func (r *Renderer) SetLogicalSize(w, h int) int {
 	return int(ret)
 }
 
+// Get device independent resolution for rendering, as set by
+// SetLogicalSize. Returns (0, 0) if no logical size is set.
+func (r *Renderer) GetLogicalSize() (int, int) {
+	var w, h C.int
+	C.SDL_RenderGetLogicalSize(r.cRenderer, &w, &h)
+	return int(w), int(h)
+}
+
+// Set the drawing area for rendering on the current target. Passing nil
+// sets the viewport to the entire target.
+func (r *Renderer) SetViewport(rect *Rect) error {
+	if C.SDL_RenderSetViewport(r.cRenderer, (*C.SDL_Rect)(cast(rect))) != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
+// Get the drawing area for the current target.
+func (r *Renderer) GetViewport() Rect {
+	var rect Rect
+	C.SDL_RenderGetViewport(r.cRenderer, (*C.SDL_Rect)(cast(&rect)))
+	return rect
+}
+
+// Set the clip rectangle for the current target. Passing nil disables
+// clipping.
+func (r *Renderer) SetClipRect(rect *Rect) error {
+	if C.SDL_RenderSetClipRect(r.cRenderer, (*C.SDL_Rect)(cast(rect))) != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
+// Get the clip rectangle for the current target. An empty rectangle
+// means clipping is disabled.
+func (r *Renderer) GetClipRect() Rect {
+	var rect Rect
+	C.SDL_RenderGetClipRect(r.cRenderer, (*C.SDL_Rect)(cast(&rect)))
+	return rect
+}
+
+// Set the drawing scale for rendering on the current target. The
+// drawing coordinates are scaled by the x/y scaling factors before they
+// are used by the renderer.
+func (r *Renderer) SetScale(scaleX, scaleY float32) error {
+	if C.SDL_RenderSetScale(r.cRenderer, C.float(scaleX), C.float(scaleY)) != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
+// Get the drawing scale for the current target.
+// Returns (scaleX, scaleY)
+func (r *Renderer) GetScale() (float32, float32) {
+	var x, y C.float
+	C.SDL_RenderGetScale(r.cRenderer, &x, &y)
+	return float32(x), float32(y)
+}
+
+// Determines whether the renderer supports the use of render targets.
+func (r *Renderer) TargetSupported() bool {
+	return C.SDL_RenderTargetSupported(r.cRenderer) == C.SDL_TRUE
+}
+
+// Set a texture as the current rendering target. The texture must have
+// been created with TEXTUREACCESS_TARGET. Passing nil restores the
+// default target (the window).
+func (r *Renderer) SetTarget(t *Texture) error {
+	var ctexture *C.SDL_Texture
+	if t != nil {
+		ctexture = t.cTexture
+	}
+	if C.SDL_SetRenderTarget(r.cRenderer, ctexture) != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
+// Get the current render target, or nil for the default render target.
+func (r *Renderer) GetTarget() *Texture {
+	return wrapTexture(C.SDL_GetRenderTarget(r.cRenderer))
+}
+
+// Get the output size in pixels of the current rendering target.
+// Returns (width, height, err)
+func (r *Renderer) GetOutputSize() (int, int, error) {
+	var w, h C.int
+	if C.SDL_GetRendererOutputSize(r.cRenderer, &w, &h) != 0 {
+		return 0, 0, NewSDLError()
+	}
+	return int(w), int(h), nil
+}
+
+// Read pixels from the current rendering target. The rectangle is in
+// pixels of the target; nil reads the whole target. Format is one of
+// the PIXELFORMAT_* constants; planar YUV formats (PIXELFORMAT_YV12,
+// PIXELFORMAT_IYUV, PIXELFORMAT_NV12 and PIXELFORMAT_NV21) are not
+// supported.
+//
+// This is a very slow operation, and should not be used frequently.
+//
+// Return values are (pixels, pitch, err)
+func (r *Renderer) ReadPixels(rect *Rect, format uint32) ([]byte, int, error) {
+	if rect == nil {
+		w, h, err := r.GetOutputSize()
+		if err != nil {
+			return nil, 0, err
+		}
+		rect = &Rect{0, 0, int32(w), int32(h)}
+	}
+	bpp := BytesPerPixel(format)
+	if bpp == 0 {
+		return nil, 0, &SDLError{"ReadPixels: unknown pixel format"}
+	}
+	if IsPixelFormatFourCC(format) && bpp == 1 {
+		// SDL writes the chroma planes after the Y plane, past the end
+		// of a buffer sized from the bytes per pixel.
+		return nil, 0, &SDLError{"ReadPixels: planar YUV formats are not supported"}
+	}
+	pitch := int(rect.W) * bpp
+	pixels := make([]byte, pitch*int(rect.H))
+	if len(pixels) == 0 {
+		return pixels, pitch, nil
+	}
+	ret := C.SDL_RenderReadPixels(r.cRenderer, (*C.SDL_Rect)(cast(rect)),
+		C.Uint32(format), unsafe.Pointer(&pixels[0]), C.int(pitch))
+	if ret != 0 {
+		return nil, 0, NewSDLError()
+	}
+	return pixels, pitch, nil
+}
+
+// Read pixels from the current rendering target into an image, for
+// screenshots or comparing frames against reference images. The
+// rectangle is interpreted as in ReadPixels.
+func (r *Renderer) ReadImage(rect *Rect) (*image.RGBA, error) {
+	if rect == nil {
+		w, h, err := r.GetOutputSize()
+		if err != nil {
+			return nil, err
+		}
+		rect = &Rect{0, 0, int32(w), int32(h)}
+	}
+	pixels, pitch, err := r.ReadPixels(rect, PIXELFORMAT_RGBA32)
+	if err != nil {
+		return nil, err
+	}
+
+	// SDL colors are not premultiplied, image.RGBA colors are.
+	for i := 0; i+3 < len(pixels); i += 4 {
+		if a := uint32(pixels[i+3]); a != 0xFF {
+			pixels[i] = uint8(uint32(pixels[i]) * a / 0xFF)
+			pixels[i+1] = uint8(uint32(pixels[i+1]) * a / 0xFF)
+			pixels[i+2] = uint8(uint32(pixels[i+2]) * a / 0xFF)
+		}
+	}
+
+	return &image.RGBA{
+		Pix:    pixels,
+		Stride: pitch,
+		Rect:   image.Rect(0, 0, int(rect.W), int(rect.H)),
+	}, nil
+}
+
 func (r *Renderer) Destroy() {
 	C.SDL_DestroyRenderer(r.cRenderer)
 }
@@ -203,9 +420,122 @@ func (t *Texture) Update(rect *Rect, pixels interface{}, pitch int) {
 }
 
 func (t *Texture) Destroy() {
+	if t.locked != nil {
+		t.Unlock()
+	}
 	C.SDL_DestroyTexture(t.cTexture)
 }
 
+// The pixels of a texture locked with Lock. They are write-only: the
+// texture memory is not guaranteed to hold the old contents. Every
+// method fails once the texture has been unlocked, so the pixels can
+// not be written after SDL has taken them back.
+type LockedPixels struct {
+	pixels []byte // nil once the texture is unlocked
+	pitch  int
+}
+
+// Returns the number of bytes from the start of one row of the locked
+// region to the next. Row n starts at offset n*pitch.
+func (p *LockedPixels) Pitch() int {
+	return p.pitch
+}
+
+// Returns the size of the locked region in bytes, or 0 once the texture
+// has been unlocked.
+func (p *LockedPixels) Len() int {
+	return len(p.pixels)
+}
+
+// Copies b into the locked pixels at offset off, implementing
+// io.WriterAt. Returns an error if the texture has been unlocked or b
+// does not fit.
+func (p *LockedPixels) WriteAt(b []byte, off int64) (int, error) {
+	if p.pixels == nil {
+		return 0, &SDLError{"WriteAt: texture is not locked"}
+	}
+	if off < 0 || off > int64(len(p.pixels)) {
+		return 0, &SDLError{"WriteAt: offset out of range"}
+	}
+	n := copy(p.pixels[off:], b)
+	if n < len(b) {
+		return n, &SDLError{"WriteAt: write past the end of the locked region"}
+	}
+	return n, nil
+}
+
+// Lock a portion of a TEXTUREACCESS_STREAMING texture for write-only
+// pixel access. Passing nil locks the entire texture. Every pixel in
+// the region must be written, since the old texture contents are not
+// kept. Locking a texture that is already locked returns an error.
+//
+// The pixels can only be written until Unlock is called; after that,
+// their methods return errors. WithLock hands out the pixels as a
+// slice instead, for the duration of a function.
+func (t *Texture) Lock(rect *Rect) (*LockedPixels, error) {
+	pixels, pitch, err := t.lock(rect)
+	if err != nil {
+		return nil, err
+	}
+	t.locked = &LockedPixels{pixels: pixels, pitch: pitch}
+	return t.locked, nil
+}
+
+func (t *Texture) lock(rect *Rect) ([]byte, int, error) {
+	if t.locked != nil {
+		return nil, 0, &SDLError{"Lock: texture is already locked"}
+	}
+
+	format, _, w, h, err := t.Query()
+	if err != nil {
+		return nil, 0, err
+	}
+	if rect != nil {
+		w, h = int(rect.W), int(rect.H)
+	}
+
+	var cpixels unsafe.Pointer
+	var cpitch C.int
+	if C.SDL_LockTexture(t.cTexture, (*C.SDL_Rect)(cast(rect)), &cpixels, &cpitch) != 0 {
+		return nil, 0, NewSDLError()
+	}
+
+	// The last row of a sub-rectangle may end before the pitch does, at
+	// the very end of the texture memory.
+	n := 0
+	if w > 0 && h > 0 {
+		n = int(cpitch)*(h-1) + w*BytesPerPixel(format)
+	}
+	return (*[1 << 30]byte)(cpixels)[:n:n], int(cpitch), nil
+}
+
+// Unlock a texture locked with Lock, uploading the changes. The pixels
+// returned by Lock can not be written afterwards. Unlocking a texture
+// that is not locked panics.
+func (t *Texture) Unlock() {
+	if t.locked == nil {
+		panic("sdl: Unlock of unlocked texture")
+	}
+	t.locked.pixels = nil
+	t.locked = nil
+	C.SDL_UnlockTexture(t.cTexture)
+}
+
+// Locks the texture, calls f with the locked pixels and unlocks it
+// again, even if f panics. Row n of the region starts at
+// pixels[n*pitch]. The slice is only valid while f runs and, unlike
+// the pixels returned by Lock, is not checked: f must not keep it.
+func (t *Texture) WithLock(rect *Rect, f func(pixels []byte, pitch int)) error {
+	pixels, pitch, err := t.lock(rect)
+	if err != nil {
+		return err
+	}
+	t.locked = &LockedPixels{pitch: pitch}
+	defer t.Unlock()
+	f(pixels, pitch)
+	return nil
+}
+
 func (t *Texture) SetBlendMode(blendmode int) bool {
 	ret := C.SDL_SetTextureBlendMode(t.cTexture, C.SDL_BlendMode(blendmode))
 	return int(ret) == 0
@@ -233,6 +563,18 @@ func (t *Texture) Unbind() bool {
 	return int(C.SDL_GL_UnbindTexture(t.cTexture)) == 0
 }
 
+// Query the attributes of a texture: its PIXELFORMAT_* format,
+// TEXTUREACCESS_* access mode and size in pixels.
+// Return values are (format, access, w, h, err)
+func (t *Texture) Query() (uint32, int, int, int, error) {
+	var format C.Uint32
+	var access, w, h C.int
+	if C.SDL_QueryTexture(t.cTexture, &format, &access, &w, &h) != 0 {
+		return 0, 0, 0, 0, NewSDLError()
+	}
+	return uint32(format), int(access), int(w), int(h), nil
+}
+
 // Query returns (w, h)
 func (t *Texture) GetSize() (int, int) {
 	var w C.int
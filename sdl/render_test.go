@@ -0,0 +1,153 @@
+package sdl
+
+import "testing"
+
+// Creates a software renderer on a hidden window of the dummy video
+// driver, so the tests run on headless machines.
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Setenv("SDL_VIDEODRIVER", "dummy")
+	if Init(INIT_VIDEO) != 0 {
+		t.Skip("dummy video driver unavailable: " + GetError())
+	}
+	t.Cleanup(Quit)
+
+	w := CreateWindow("test", 0, 0, 64, 64, WINDOW_HIDDEN)
+	if w == nil {
+		t.Fatal(GetError())
+	}
+	t.Cleanup(w.Destroy)
+
+	r := CreateRenderer(w, -1, RENDERER_SOFTWARE|RENDERER_TARGETTEXTURE)
+	if r == nil {
+		t.Fatal(GetError())
+	}
+	t.Cleanup(r.Destroy)
+	return r
+}
+
+func TestRenderTarget(t *testing.T) {
+	r := newTestRenderer(t)
+	if !r.TargetSupported() {
+		t.Fatal("software renderer does not support render targets")
+	}
+
+	tex := r.CreateTexture(PIXELFORMAT_ARGB8888, TEXTUREACCESS_TARGET, 32, 16)
+	if tex == nil {
+		t.Fatal(GetError())
+	}
+	defer tex.Destroy()
+
+	if err := r.SetTarget(tex); err != nil {
+		t.Fatal(err)
+	}
+	if target := r.GetTarget(); target == nil || target.cTexture != tex.cTexture {
+		t.Errorf("GetTarget() = %v, want the target texture", target)
+	}
+	if w, h, err := r.GetOutputSize(); err != nil || w != 32 || h != 16 {
+		t.Errorf("GetOutputSize() = %d, %d, %v, want 32, 16, nil", w, h, err)
+	}
+
+	r.SetDrawColor(Color{R: 0x10, G: 0x20, B: 0x30, A: 0xFF})
+	r.Clear()
+	img, err := r.ReadImage(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.RGBAAt(5, 5); got.R != 0x10 || got.G != 0x20 || got.B != 0x30 || got.A != 0xFF {
+		t.Errorf("pixel read back from target = %v, want the clear color", got)
+	}
+
+	if err := r.SetTarget(nil); err != nil {
+		t.Fatal(err)
+	}
+	if target := r.GetTarget(); target != nil {
+		t.Errorf("GetTarget() = %v after resetting, want nil", target)
+	}
+}
+
+func TestRenderViewportClipScale(t *testing.T) {
+	r := newTestRenderer(t)
+	tex := r.CreateTexture(PIXELFORMAT_ARGB8888, TEXTUREACCESS_TARGET, 32, 32)
+	if tex == nil {
+		t.Fatal(GetError())
+	}
+	defer tex.Destroy()
+	if err := r.SetTarget(tex); err != nil {
+		t.Fatal(err)
+	}
+
+	viewport := Rect{X: 4, Y: 2, W: 16, H: 8}
+	if err := r.SetViewport(&viewport); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.GetViewport(); got != viewport {
+		t.Errorf("GetViewport() = %v, want %v", got, viewport)
+	}
+
+	clip := Rect{X: 1, Y: 1, W: 6, H: 5}
+	if err := r.SetClipRect(&clip); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.GetClipRect(); got != clip {
+		t.Errorf("GetClipRect() = %v, want %v", got, clip)
+	}
+	if err := r.SetClipRect(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.GetClipRect(); !got.Empty() {
+		t.Errorf("GetClipRect() = %v after disabling clipping, want an empty rect", got)
+	}
+
+	if err := r.SetScale(2, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if x, y := r.GetScale(); x != 2 || y != 0.5 {
+		t.Errorf("GetScale() = %v, %v, want 2, 0.5", x, y)
+	}
+}
+
+func TestTextureLock(t *testing.T) {
+	r := newTestRenderer(t)
+	tex := r.CreateTexture(PIXELFORMAT_ARGB8888, TEXTUREACCESS_STREAMING, 8, 4)
+	if tex == nil {
+		t.Fatal(GetError())
+	}
+	defer tex.Destroy()
+
+	pixels, err := tex.Lock(&Rect{X: 2, Y: 1, W: 4, H: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tex.Lock(nil); err == nil {
+		t.Error("Lock() of a locked texture succeeded")
+	}
+	if want := pixels.Pitch() + 4*4; pixels.Len() != want {
+		t.Errorf("Len() = %d, want %d", pixels.Len(), want)
+	}
+	row := make([]byte, 4*4)
+	if n, err := pixels.WriteAt(row, int64(pixels.Pitch())); n != len(row) || err != nil {
+		t.Errorf("WriteAt() = %d, %v, want %d, nil", n, err, len(row))
+	}
+	if _, err := pixels.WriteAt(row, int64(pixels.Pitch()+1)); err == nil {
+		t.Error("WriteAt() past the end of the region succeeded")
+	}
+
+	tex.Unlock()
+	if _, err := pixels.WriteAt(row, 0); err == nil {
+		t.Error("WriteAt() after Unlock succeeded")
+	}
+	if pixels.Len() != 0 {
+		t.Errorf("Len() = %d after Unlock, want 0", pixels.Len())
+	}
+
+	called := false
+	err = tex.WithLock(nil, func(pixels []byte, pitch int) {
+		called = true
+		if len(pixels) != 3*pitch+8*4 {
+			t.Errorf("len(pixels) = %d, want %d", len(pixels), 3*pitch+8*4)
+		}
+	})
+	if err != nil || !called {
+		t.Errorf("WithLock() = %v, called = %v", err, called)
+	}
+}
@@ -57,6 +57,21 @@ func (rwops *RWops) Free() {
 	rwops.mem = nil
 }
 
+// FIXME: Ideally, this should NOT be a public function, but it is needed in the packages "mixer" and "ttf" ...
+// Returns the underlying *C.SDL_RWops.
+func (rwops *RWops) CRWops() unsafe.Pointer {
+	return unsafe.Pointer(rwops.cRWops)
+}
+
+// Marks the RWops as closed by C code it was handed to with freesrc
+// set, clearing the C pointer so that a later Free does not free it
+// twice. Memory passed to RWFromMem stays referenced, since C may
+// still be reading from it; keep the RWops around for as long as that
+// is the case.
+func (rwops *RWops) Detach() {
+	rwops.cRWops = nil
+}
+
 func RWFromFile(file string, mode string) *RWops {
 	cfile, cmode := C.CString(file), C.CString(mode)
 	defer C.free(unsafe.Pointer(cfile))
@@ -253,8 +253,8 @@ type ControllerDeviceEvent struct {
 type TouchFingerEvent struct {
 	Type      uint32 /**< ::FINGERMOTION or ::FINGERDOWN or ::FINGERUP */
 	Timestamp uint32
-	TouchId   int32 /**< The touch device id */
-	FingerId  int32
+	TouchId   int64 /**< The touch device id */
+	FingerId  int64
 	X         float32 /**< Normalized in the range 0...1 */
 	Y         float32 /**< Normalized in the range 0...1 */
 	Dx        float32 /**< Normalized in the range 0...1 */
@@ -268,7 +268,7 @@ type TouchFingerEvent struct {
 type MultiGestureEvent struct {
 	Type       uint32 /**< ::MULTIGESTURE */
 	Timestamp  uint32
-	TouchId    int32 /**< The touch device index */
+	TouchId    int64 /**< The touch device index */
 	Dtheta     float32
 	Ddist      float32
 	X          float32
@@ -283,8 +283,8 @@ type MultiGestureEvent struct {
 type DollarGestureEvent struct {
 	Type       uint32 /**< ::DOLLARGESTURE */
 	Timestamp  uint32
-	TouchId    int32 /**< The touch device id */
-	GestureId  int32
+	TouchId    int64 /**< The touch device id */
+	GestureId  int64
 	NumFingers uint32
 	Error      float32
 	X          float32 /**< Normalized center of gesture */
@@ -292,14 +292,16 @@ type DollarGestureEvent struct {
 }
 
 /**
- *  \brief An event used to request a file open by the system (event.drop.*)
- *         This event is disabled by default, you can enable it with SDL_EventState()
- *  \note If you enable this event, you must free the filename in the event.
+ *  \brief Audio device event structure (event.adevice.*)
  */
-type DropEvent struct {
-	Type      uint32 /**< ::DROPFILE */
+type AudioDeviceEvent struct {
+	Type      uint32 /**< ::AUDIODEVICEADDED, or ::AUDIODEVICEREMOVED */
 	Timestamp uint32
-	File      *byte /**< The file name, which should be freed with SDL_free() */
+	Which     uint32 /**< The audio device index for the ADDED event, AudioDeviceID for the REMOVED event */
+	IsCapture uint8  /**< zero if an output device, non-zero if a capture device. */
+	Padding1  uint8
+	Padding2  uint8
+	Padding3  uint8
 }
 
 type QuitEvent struct {
@@ -317,8 +319,8 @@ type UserEvent struct {
 	Timestamp uint32
 	WindowID  uint32
 	Code      int32
-	Data1     *byte
-	Data2     *byte
+	Data1     uintptr
+	Data2     uintptr
 }
 
 type SysWMmsg struct{}
@@ -121,6 +121,20 @@ func BlitSurface(src *Surface, srcrect *Rect, dst *Surface, dstrect *Rect) int {
 	return dst.Blit(dstrect, src, srcrect)
 }
 
+// Performs a scaled blit from the source surface to the destination
+// surface. A nil srcrect copies the whole source surface, a nil dstrect
+// fills the whole destination surface. The final blit rectangle is
+// saved in dstrect after clipping.
+func (dst *Surface) BlitScaled(dstrect *Rect, src *Surface, srcrect *Rect) int {
+	var ret C.int
+	ret = C.SDL_UpperBlitScaled(
+		src.cSurface,
+		(*C.SDL_Rect)(cast(srcrect)),
+		dst.cSurface,
+		(*C.SDL_Rect)(cast(dstrect)))
+	return int(ret)
+}
+
 // This function performs a fast fill of the given rectangle with some color.
 func (dst *Surface) FillRect(dstrect *Rect, color uint32) int {
 	var ret = C.SDL_FillRect(
@@ -137,6 +151,45 @@ func (s *Surface) SetColorKey(flags uint32, ColorKey uint32) int {
 	return status
 }
 
+// Sets an additional alpha value used in blit operations.
+func (s *Surface) SetAlphaMod(alpha uint8) int {
+	return int(C.SDL_SetSurfaceAlphaMod(s.cSurface, C.Uint8(alpha)))
+}
+
+// Gets the additional alpha value used in blit operations.
+// Returns (alpha, status)
+func (s *Surface) GetAlphaMod() (uint8, int) {
+	var alpha C.Uint8
+	status := int(C.SDL_GetSurfaceAlphaMod(s.cSurface, &alpha))
+	return uint8(alpha), status
+}
+
+// Sets an additional color value used in blit operations.
+func (s *Surface) SetColorMod(r, g, b uint8) int {
+	return int(C.SDL_SetSurfaceColorMod(s.cSurface, C.Uint8(r), C.Uint8(g), C.Uint8(b)))
+}
+
+// Gets the additional color value used in blit operations.
+// Returns (r, g, b, status)
+func (s *Surface) GetColorMod() (uint8, uint8, uint8, int) {
+	var r, g, b C.Uint8
+	status := int(C.SDL_GetSurfaceColorMod(s.cSurface, &r, &g, &b))
+	return uint8(r), uint8(g), uint8(b), status
+}
+
+// Sets the BLENDMODE_* used for blit operations.
+func (s *Surface) SetBlendMode(blendmode int) int {
+	return int(C.SDL_SetSurfaceBlendMode(s.cSurface, C.SDL_BlendMode(blendmode)))
+}
+
+// Gets the BLENDMODE_* used for blit operations.
+// Returns (blendmode, status)
+func (s *Surface) GetBlendMode() (int, int) {
+	var blendmode C.SDL_BlendMode
+	status := int(C.SDL_GetSurfaceBlendMode(s.cSurface, &blendmode))
+	return int(blendmode), status
+}
+
 // Gets the clipping rectangle for a surface.
 func (s *Surface) GetClipRect(r *Rect) {
 	C.SDL_GetClipRect(s.cSurface, (*C.SDL_Rect)(cast(r)))
@@ -155,6 +208,52 @@ func Load(file string) *Surface {
 	return wrapSurface(screen)
 }
 
+// Creates a new surface with the pixels of this one converted to the
+// given format, optimized for fast blitting onto surfaces of that
+// format. Returns nil if an error occurred.
+func (s *Surface) Convert(format *PixelFormat, flags uint32) *Surface {
+	return wrapSurface(C.SDL_ConvertSurface(s.cSurface, (*C.SDL_PixelFormat)(cast(format)), C.Uint32(flags)))
+}
+
+// Creates a new surface with the pixels of this one converted to a
+// PIXELFORMAT_* format. Returns nil if an error occurred.
+func (s *Surface) ConvertFormat(pixelFormat uint32, flags uint32) *Surface {
+	return wrapSurface(C.SDL_ConvertSurfaceFormat(s.cSurface, C.Uint32(pixelFormat), C.Uint32(flags)))
+}
+
+// Saves a surface to a BMP file.
+func (s *Surface) SaveBMP(file string) error {
+	dst := RWFromFile(file, "wb")
+	if dst == nil {
+		return NewSDLError()
+	}
+	return s.SaveBMP_RW(dst, true)
+}
+
+// Saves a surface as BMP to a seekable SDL data stream. If freedst is
+// true, the stream is closed afterwards even on error.
+func (s *Surface) SaveBMP_RW(dst *RWops, freedst bool) error {
+	ret := C.SDL_SaveBMP_RW(s.cSurface, dst.cRWops, C.int(bool2int(freedst)))
+	if freedst {
+		dst.cRWops = nil
+		dst.mem = nil
+	}
+	if ret != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
+// Saves a surface to a PNG file (using IMG_SavePNG).
+func (s *Surface) SavePNG(file string) error {
+	cfile := C.CString(file)
+	defer C.free(unsafe.Pointer(cfile))
+	if C.IMG_SavePNG(s.cSurface, cfile) != 0 {
+		return NewSDLError()
+	}
+	return nil
+}
+
 // Creates an empty Surface.
 func CreateRGBSurface(flags uint32, width int, height int, bpp int, Rmask uint32, Gmask uint32, Bmask uint32, Amask uint32) *Surface {
 	p := C.SDL_CreateRGBSurface(C.Uint32(flags), C.int(width), C.int(height), C.int(bpp),
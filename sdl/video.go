@@ -164,3 +164,22 @@ func MapRGBA(format *PixelFormat, r, g, b, a uint8) uint32 {
 func GetRGBA(color uint32, format *PixelFormat, r, g, b, a *uint8) {
 	C.SDL_GetRGBA(C.Uint32(color), (*C.SDL_PixelFormat)(cast(format)), (*C.Uint8)(r), (*C.Uint8)(g), (*C.Uint8)(b), (*C.Uint8)(a))
 }
+
+// Returns true if the pixel format is a FourCC (YUV) format.
+func IsPixelFormatFourCC(format uint32) bool {
+	return format != 0 && (format>>28)&0x0F != 1
+}
+
+// Returns the number of bytes used to store one pixel of the given
+// pixel format. For planar YUV formats this is the size of the Y plane
+// samples.
+func BytesPerPixel(format uint32) int {
+	if IsPixelFormatFourCC(format) {
+		switch format {
+		case PIXELFORMAT_YUY2, PIXELFORMAT_UYVY, PIXELFORMAT_YVYU:
+			return 2
+		}
+		return 1
+	}
+	return int(format & 0xFF)
+}
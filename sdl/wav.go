@@ -0,0 +1,127 @@
+package sdl
+
+/*
+  SDL Go Wrapper
+
+  Simple DirectMedia Layer
+  Copyright (C) 1997-2013 Sam Lantinga <slouken@libsdl.org>
+
+  This software is provided 'as-is', without any express or implied
+  warranty.  In no event will the authors be held liable for any damages
+  arising from the use of this software.
+
+  Permission is granted to anyone to use this software for any purpose,
+  including commercial applications, and to alter it and redistribute it
+  freely, subject to the following restrictions:
+
+  1. The origin of this software must not be misrepresented; you must not
+     claim that you wrote the original software. If you use this software
+     in a product, an acknowledgment in the product documentation would be
+     appreciated but is not required.
+  2. Altered source versions must be plainly marked as such, and must not be
+     misrepresented as being the original software.
+  3. This notice may not be removed or altered from any source distribution.
+*/
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// WAVE format tags
+const (
+	wavFormatPCM   = 1
+	wavFormatFloat = 3
+)
+
+// Writes PCM audio as a WAVE file. Format, Channels and Freq of spec
+// describe the data, whose length must be a multiple of the frame
+// size. WAVE files are little-endian and only store 8-bit samples
+// unsigned and wider samples signed, so big-endian data is byte
+// swapped, AUDIO_S8 is stored as unsigned and AUDIO_U16 as signed.
+// Float audio is stored with the IEEE float format tag. This does not
+// need SDL to be initialized.
+func WriteWAV(w io.Writer, spec AudioSpec, data []byte) error {
+	bits := int(Audio_BitSize(uint32(spec.Format)))
+	if bits == 0 || bits%8 != 0 || spec.Channels == 0 || spec.Freq <= 0 {
+		return &SDLError{"WriteWAV: unsupported audio spec"}
+	}
+	sampleSize := bits / 8
+	frameSize := sampleSize * int(spec.Channels)
+	if len(data)%frameSize != 0 {
+		return &SDLError{"WriteWAV: data is not a whole number of frames"}
+	}
+
+	format := spec.Format
+	tag := uint16(wavFormatPCM)
+	if Audio_IsFloat(uint32(format)) {
+		tag = wavFormatFloat
+	}
+
+	// Rewrite the samples in the byte order and signedness of WAVE, in
+	// a copy so the caller's data is left alone.
+	out := data
+	swap := sampleSize > 1 && Audio_IsBigEndian(uint32(format))
+	flip := tag == wavFormatPCM && (sampleSize == 1) == Audio_IsSigned(uint32(format))
+	if swap || flip {
+		out = make([]byte, len(data))
+		copy(out, data)
+		for i := 0; i < len(out); i += sampleSize {
+			sample := out[i : i+sampleSize]
+			if swap {
+				for j, k := 0, sampleSize-1; j < k; j, k = j+1, k-1 {
+					sample[j], sample[k] = sample[k], sample[j]
+				}
+			}
+			if flip {
+				// The sign bit is in the most significant byte, which
+				// is last after swapping to little-endian.
+				sample[sampleSize-1] ^= 0x80
+			}
+		}
+	}
+
+	fmtSize := 16
+	factSize := 0
+	if tag == wavFormatFloat {
+		// Non-PCM formats have a cbSize field and a fact chunk.
+		fmtSize = 18
+		factSize = 8 + 4
+	}
+	pad := len(out) % 2
+
+	header := make([]byte, 0, 12+8+fmtSize+factSize+8)
+	le := binary.LittleEndian
+	header = append(header, "RIFF"...)
+	header = le.AppendUint32(header, uint32(4+8+fmtSize+factSize+8+len(out)+pad))
+	header = append(header, "WAVE"...)
+	header = append(header, "fmt "...)
+	header = le.AppendUint32(header, uint32(fmtSize))
+	header = le.AppendUint16(header, tag)
+	header = le.AppendUint16(header, uint16(spec.Channels))
+	header = le.AppendUint32(header, uint32(spec.Freq))
+	header = le.AppendUint32(header, uint32(int(spec.Freq)*frameSize))
+	header = le.AppendUint16(header, uint16(frameSize))
+	header = le.AppendUint16(header, uint16(bits))
+	if tag == wavFormatFloat {
+		header = le.AppendUint16(header, 0)
+		header = append(header, "fact"...)
+		header = le.AppendUint32(header, 4)
+		header = le.AppendUint32(header, uint32(len(out)/frameSize))
+	}
+	header = append(header, "data"...)
+	header = le.AppendUint32(header, uint32(len(out)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(out); err != nil {
+		return err
+	}
+	if pad != 0 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
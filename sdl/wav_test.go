@@ -0,0 +1,88 @@
+package sdl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func float32Bytes(order binary.AppendByteOrder, values ...float32) []byte {
+	b := make([]byte, 0, 4*len(values))
+	for _, v := range values {
+		b = order.AppendUint32(b, math.Float32bits(v))
+	}
+	return b
+}
+
+func TestWriteWAV(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       AudioSpec
+		data       []byte
+		wantFormat AudioFormat
+		want       []byte
+	}{{
+		// Stored unsigned, with a pad byte after the odd-sized data.
+		name:       "S8",
+		spec:       AudioSpec{Freq: 8000, Format: AUDIO_S8, Channels: 1},
+		data:       []byte{0x00, 0x7F, 0x80},
+		wantFormat: AUDIO_U8,
+		want:       []byte{0x80, 0xFF, 0x00},
+	}, {
+		// Stored signed and little-endian.
+		name:       "U16MSB",
+		spec:       AudioSpec{Freq: 22050, Format: AUDIO_U16MSB, Channels: 2},
+		data:       []byte{0x80, 0x00, 0xFF, 0xFF, 0x00, 0x00, 0x12, 0x34},
+		wantFormat: AUDIO_S16LSB,
+		want:       []byte{0x00, 0x00, 0xFF, 0x7F, 0x00, 0x80, 0x34, 0x92},
+	}, {
+		name:       "F32MSB",
+		spec:       AudioSpec{Freq: 48000, Format: AUDIO_F32MSB, Channels: 2},
+		data:       float32Bytes(binary.BigEndian, 0, 0.5, -1, 0.25),
+		wantFormat: AUDIO_F32LSB,
+		want:       float32Bytes(binary.LittleEndian, 0, 0.5, -1, 0.25),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte(nil), tt.data...)
+			var buf bytes.Buffer
+			if err := WriteWAV(&buf, tt.spec, data); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, tt.data) {
+				t.Errorf("WriteWAV modified its input: %x, want %x", data, tt.data)
+			}
+			file := buf.Bytes()
+			if size := binary.LittleEndian.Uint32(file[4:]); int(size) != len(file)-8 {
+				t.Errorf("RIFF size = %d, want %d", size, len(file)-8)
+			}
+			if hasFact := bytes.Contains(file, []byte("fact")); hasFact != Audio_IsFloat(uint32(tt.spec.Format)) {
+				t.Errorf("fact chunk present = %v for %s", hasFact, tt.name)
+			}
+
+			spec, samples, err := LoadWAV_RW(RWFromMem(file), true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if spec.Format != tt.wantFormat || spec.Channels != tt.spec.Channels || spec.Freq != tt.spec.Freq {
+				t.Errorf("loaded spec = %v format, %d channels, %d Hz, want %v, %d, %d",
+					spec.Format, spec.Channels, spec.Freq, tt.wantFormat, tt.spec.Channels, tt.spec.Freq)
+			}
+			if !bytes.Equal(samples, tt.want) {
+				t.Errorf("loaded samples = %x, want %x", samples, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteWAVErrors(t *testing.T) {
+	spec := AudioSpec{Freq: 44100, Format: AUDIO_S16LSB, Channels: 2}
+	if err := WriteWAV(&bytes.Buffer{}, spec, make([]byte, 6)); err == nil {
+		t.Error("WriteWAV accepted a partial frame")
+	}
+	spec.Channels = 0
+	if err := WriteWAV(&bytes.Buffer{}, spec, nil); err == nil {
+		t.Error("WriteWAV accepted a spec without channels")
+	}
+}
@@ -0,0 +1,333 @@
+package ttf
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"unsafe"
+
+	"github.com/krig/Go-SDL2/sdl"
+)
+
+// Horizontal alignment of the lines drawn by a GlyphCache, relative to
+// the x coordinate passed to Draw.
+const (
+	ALIGN_LEFT = iota
+	ALIGN_CENTER
+	ALIGN_RIGHT
+)
+
+// The size of the atlas textures glyphs are packed into.
+const glyphPageSize = 512
+
+// Space left between glyphs in an atlas, so that filtering never
+// samples a neighbour.
+const glyphPadding = 1
+
+// A row of glyphs in an atlas page, filled from left to right.
+type glyphShelf struct {
+	y, h, x int
+}
+
+type glyphPage struct {
+	tex     *sdl.Texture
+	shelves []glyphShelf
+	bottom  int // Top of the free space below the shelves
+}
+
+type cachedGlyph struct {
+	page    int      // Index into pages, or -1 for glyphs with no pixels
+	src     sdl.Rect // Where the glyph is in its page
+	dx, dy  int      // Offset of the glyph pixels from the pen position and the line top
+	advance int
+}
+
+// A GlyphCache draws text from a font with a renderer.  Every glyph is
+// rendered only once, in white, and packed into shared atlas textures;
+// drawing a string then only takes one Renderer.Copy per glyph, with
+// the color applied as a color and alpha modulation.  This keeps text
+// that changes every frame, like score counters, cheap.
+//
+// The font must not be closed, or its style, outline or size changed,
+// while the cache is in use.
+type GlyphCache struct {
+	font       *Font
+	renderer   *sdl.Renderer
+	glyphs     map[rune]*cachedGlyph
+	pages      []*glyphPage
+	lineLayout bool // See glyphOrigin
+}
+
+// Creates an empty glyph cache for drawing text from a font with a
+// renderer.
+func NewGlyphCache(font *Font, renderer *sdl.Renderer) *GlyphCache {
+	return &GlyphCache{
+		font:       font,
+		renderer:   renderer,
+		glyphs:     make(map[rune]*cachedGlyph),
+		lineLayout: glyphLineLayout(),
+	}
+}
+
+// Frees the atlas textures.  The cache can still be used afterwards; it
+// then starts over empty.
+func (gc *GlyphCache) Free() {
+	for _, p := range gc.pages {
+		p.tex.Destroy()
+	}
+	gc.pages = nil
+	gc.glyphs = make(map[rune]*cachedGlyph)
+}
+
+// Finds room for a w by h rectangle in an atlas page, creating a new
+// page if needed.  Returns the page index and the position.
+func (gc *GlyphCache) allocate(w, h int) (int, int, int, error) {
+	w += glyphPadding
+	h += glyphPadding
+	if w > glyphPageSize || h > glyphPageSize {
+		return 0, 0, 0, errors.New("ttf: glyph too large for the glyph cache")
+	}
+	for i, p := range gc.pages {
+		for j := range p.shelves {
+			s := &p.shelves[j]
+			if h <= s.h && s.x+w <= glyphPageSize {
+				x := s.x
+				s.x += w
+				return i, x, s.y, nil
+			}
+		}
+		if p.bottom+h <= glyphPageSize {
+			p.shelves = append(p.shelves, glyphShelf{y: p.bottom, h: h, x: w})
+			p.bottom += h
+			return i, 0, p.bottom - h, nil
+		}
+	}
+
+	tex := gc.renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, glyphPageSize, glyphPageSize)
+	if tex == nil {
+		return 0, 0, 0, sdl.NewSDLError()
+	}
+	tex.SetBlendMode(sdl.BLENDMODE_BLEND)
+	gc.pages = append(gc.pages, &glyphPage{
+		tex:     tex,
+		shelves: []glyphShelf{{y: 0, h: h, x: w}},
+		bottom:  h,
+	})
+	return len(gc.pages) - 1, 0, 0, nil
+}
+
+// Returns the cached glyph for a rune, rasterizing it first if needed.
+// Runes the font does not provide are drawn as '?'.
+func (gc *GlyphCache) glyph(ch rune) (*cachedGlyph, error) {
+	if g, ok := gc.glyphs[ch]; ok {
+		return g, nil
+	}
+	if gc.font.GlyphIsProvided(ch) == 0 && ch != '?' {
+		g, err := gc.glyph('?')
+		if err == nil {
+			gc.glyphs[ch] = g
+		}
+		return g, err
+	}
+
+	minx, _, _, maxy, advance, err := gc.font.GlyphMetrics(ch)
+	if err != nil {
+		return nil, err
+	}
+	g := &cachedGlyph{page: -1, advance: advance}
+
+	s := gc.font.RenderGlyph_Blended(ch, sdl.Color{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF})
+	if s == nil {
+		return nil, sdl.NewSDLError()
+	}
+	defer s.Free()
+	if s.W > 0 && s.H > 0 {
+		conv := s.ConvertFormat(sdl.PIXELFORMAT_ARGB8888, 0)
+		if conv == nil {
+			return nil, sdl.NewSDLError()
+		}
+		defer conv.Free()
+
+		h := int(conv.H)
+		g.dx, g.dy = gc.glyphOrigin(h, minx, maxy)
+
+		// Only keep the pixels that are not fully transparent, since
+		// newer SDL_ttf versions render glyphs into a whole line.
+		bounds := alphaBounds(conv)
+		if !bounds.Empty() {
+			page, x, y, err := gc.allocate(bounds.Dx(), bounds.Dy())
+			if err != nil {
+				return nil, err
+			}
+			g.page = page
+			g.src = sdl.Rect{X: int32(x), Y: int32(y), W: int32(bounds.Dx()), H: int32(bounds.Dy())}
+			g.dx += bounds.Min.X
+			g.dy += bounds.Min.Y
+			pitch := int(conv.Pitch)
+			n := pitch * h
+			offset := bounds.Min.Y*pitch + bounds.Min.X*4
+			pixels := (*[1 << 30]byte)(conv.Pixels)[offset:n:n]
+			gc.pages[page].tex.Update(&g.src, pixels, pitch)
+		}
+	}
+
+	gc.glyphs[ch] = g
+	return g, nil
+}
+
+// Returns where the top left corner of a h pixels tall surface rendered
+// by RenderGlyph_Blended goes, relative to the pen position and the top
+// of the line.  SDL_ttf before 2.0.15 renders just the glyph bitmap,
+// which starts at minx and whose top is at maxy above the baseline.
+// Later versions render the glyph like a line of text: at least
+// Height() tall, with the pen position at the left edge, or -minx to
+// the right of it for glyphs reaching left of the pen, and the line top
+// at the top edge, or further down for glyphs reaching above it.
+func (gc *GlyphCache) glyphOrigin(h, minx, maxy int) (int, int) {
+	ascent, height := gc.font.Ascent(), gc.font.Height()
+	if !gc.lineLayout {
+		return minx, ascent - maxy
+	}
+	dx, dy := 0, 0
+	if minx < 0 {
+		dx = minx
+	}
+	if above := maxy - ascent; above > 0 {
+		dy = -above
+		if above > h-height {
+			dy = height - h
+		}
+	}
+	return dx, dy
+}
+
+// Whether the linked SDL_ttf renders glyphs like a line of text; see
+// glyphOrigin.
+func glyphLineLayout() bool {
+	major, minor, patch := LinkedVersion()
+	if major != 2 {
+		return major > 2
+	}
+	if minor != 0 {
+		return minor > 0
+	}
+	return patch >= 15
+}
+
+// Returns the smallest rectangle of an ARGB8888 surface containing all
+// of its pixels that are not fully transparent.
+func alphaBounds(s *sdl.Surface) image.Rectangle {
+	w, h := int(s.W), int(s.H)
+	pitch := int(s.Pitch)
+	n := pitch * h
+	pixels := (*[1 << 30]byte)(s.Pixels)[:n:n]
+	b := image.Rectangle{Min: image.Point{X: w, Y: h}}
+	for y := 0; y < h; y++ {
+		row := pixels[y*pitch:]
+		for x := 0; x < w; x++ {
+			p := row[x*4 : x*4+4]
+			if *(*uint32)(unsafe.Pointer(&p[0]))>>24 == 0 {
+				continue
+			}
+			if x < b.Min.X {
+				b.Min.X = x
+			}
+			if y < b.Min.Y {
+				b.Min.Y = y
+			}
+			if x >= b.Max.X {
+				b.Max.X = x + 1
+			}
+			if y >= b.Max.Y {
+				b.Max.Y = y + 1
+			}
+		}
+	}
+	return b
+}
+
+// Returns the width of one line of text, including kerning.
+func (gc *GlyphCache) lineWidth(line string) (int, error) {
+	kerning := gc.font.GetKerning() != 0
+	w := 0
+	prev := rune(-1)
+	for _, ch := range line {
+		g, err := gc.glyph(ch)
+		if err != nil {
+			return 0, err
+		}
+		if kerning && prev >= 0 {
+			if k, err := gc.font.KerningSize(prev, ch); err == nil {
+				w += k
+			}
+		}
+		w += g.advance
+		prev = ch
+	}
+	return w, nil
+}
+
+// Returns the width and height text takes up when drawn.  Lines are
+// separated by '\n'.
+//
+// Return values are (width, height, err)
+func (gc *GlyphCache) Measure(text string) (int, int, error) {
+	lines := strings.Split(text, "\n")
+	w := 0
+	for _, line := range lines {
+		lw, err := gc.lineWidth(line)
+		if err != nil {
+			return 0, 0, err
+		}
+		if lw > w {
+			w = lw
+		}
+	}
+	h := (len(lines)-1)*gc.font.LineSkip() + gc.font.Height()
+	return w, h, nil
+}
+
+// Draws text with its top at y, in the specified color.  Lines are
+// separated by '\n' and are each aligned to x as given by align
+// (ALIGN_LEFT, ALIGN_CENTER or ALIGN_RIGHT).
+func (gc *GlyphCache) Draw(text string, x, y int, color sdl.Color, align int) error {
+	kerning := gc.font.GetKerning() != 0
+	for _, line := range strings.Split(text, "\n") {
+		pen := x
+		if align != ALIGN_LEFT {
+			w, err := gc.lineWidth(line)
+			if err != nil {
+				return err
+			}
+			if align == ALIGN_CENTER {
+				pen -= w / 2
+			} else {
+				pen -= w
+			}
+		}
+
+		prev := rune(-1)
+		for _, ch := range line {
+			g, err := gc.glyph(ch)
+			if err != nil {
+				return err
+			}
+			if kerning && prev >= 0 {
+				if k, err := gc.font.KerningSize(prev, ch); err == nil {
+					pen += k
+				}
+			}
+			if g.page >= 0 {
+				tex := gc.pages[g.page].tex
+				tex.SetColorMod(color.R, color.G, color.B)
+				tex.SetAlphaMod(color.A)
+				dst := sdl.Rect{X: int32(pen + g.dx), Y: int32(y + g.dy), W: g.src.W, H: g.src.H}
+				gc.renderer.Copy(tex, &g.src, &dst)
+			}
+			pen += g.advance
+			prev = ch
+		}
+		y += gc.font.LineSkip()
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ package ttf
 import "C"
 
 import (
+	"errors"
 	"github.com/krig/Go-SDL2/sdl"
 	"unsafe"
 )
@@ -28,6 +29,14 @@ func GoSdlVersion() string {
 	return "krig SDL TTF bindings 1.0"
 }
 
+// Returns the version of the SDL_ttf library in use.
+//
+// Return values are (major, minor, patch)
+func LinkedVersion() (int, int, int) {
+	v := C.TTF_Linked_Version()
+	return int(v.major), int(v.minor), int(v.patch)
+}
+
 func wrap(cSurface *C.SDL_Surface) *sdl.Surface {
 	var s *sdl.Surface
 
@@ -42,9 +51,14 @@ func wrap(cSurface *C.SDL_Surface) *sdl.Surface {
 	return s
 }
 
+// Returned for runes SDL_ttf can not handle.
+var ErrRune = errors.New("ttf: rune outside the Basic Multilingual Plane")
+
 // A ttf or otf font.
 type Font struct {
 	cfont *C.TTF_Font
+	src   *sdl.RWops     // Data stream glyphs are loaded from
+	mem   unsafe.Pointer // C copy of the data passed to OpenFontMem
 }
 
 // Initializes SDL_ttf.
@@ -91,9 +105,66 @@ func OpenFontIndex(file string, ptsize, index int) *Font {
 	return &Font{cfont: cfont}
 }
 
+// Loads a font from an SDL data stream at the specified point size.
+// Glyphs are loaded from src as they are needed, so it must stay open
+// until the font is closed; if freesrc is true, src is closed by Close
+// (or right away on errors) and must not be used or freed by the caller
+// anymore.
+func OpenFontRW(src *sdl.RWops, freesrc bool, ptsize int) *Font {
+	return OpenFontIndexRW(src, freesrc, ptsize, 0)
+}
+
+// Loads a font from an SDL data stream containing multiple font faces
+// at the specified point size.  See OpenFontRW.
+func OpenFontIndexRW(src *sdl.RWops, freesrc bool, ptsize, index int) *Font {
+	cfreesrc := C.int(0)
+	if freesrc {
+		cfreesrc = 1
+	}
+	cfont := C.TTF_OpenFontIndexRW((*C.SDL_RWops)(src.CRWops()), cfreesrc, C.int(ptsize), C.long(index))
+	if freesrc {
+		src.Detach()
+	}
+
+	if cfont == nil {
+		return nil
+	}
+
+	return &Font{cfont: cfont, src: src}
+}
+
+// Loads a font from memory at the specified point size, e.g. from a
+// file embedded with go:embed.  The data is copied and kept until the
+// font is closed.
+func OpenFontMem(data []byte, ptsize int) *Font {
+	return OpenFontIndexMem(data, ptsize, 0)
+}
+
+// Loads a font from memory containing multiple font faces at the
+// specified point size.  See OpenFontMem.
+func OpenFontIndexMem(data []byte, ptsize, index int) *Font {
+	if len(data) == 0 {
+		return nil
+	}
+	mem := C.CBytes(data)
+	cfont := C.TTF_OpenFontIndexRW(C.SDL_RWFromConstMem(mem, C.int(len(data))), 1, C.int(ptsize), C.long(index))
+
+	if cfont == nil {
+		C.free(mem)
+		return nil
+	}
+
+	return &Font{cfont: cfont, mem: mem}
+}
+
 // Frees the pointer to the font.
 func (f *Font) Close() {
 	C.TTF_CloseFont(f.cfont)
+	if f.mem != nil {
+		C.free(f.mem)
+		f.mem = nil
+	}
+	f.src = nil
 }
 
 // Renders Latin-1 text in the specified color and returns an SDL surface.  Solid
@@ -162,6 +233,98 @@ func (font *Font) RenderUTF8_Blended(text string, color sdl.Color) *sdl.Surface
 	return wrap(surface)
 }
 
+// SDL_ttf takes glyphs as UCS-2, so only runes in the Basic
+// Multilingual Plane can be passed to it.
+func glyph(ch rune) (C.Uint16, bool) {
+	if ch < 0 || ch > 0xFFFF {
+		return 0, false
+	}
+	return C.Uint16(ch), true
+}
+
+// Renders a single glyph in the specified color and returns an SDL surface,
+// or nil if the rune is outside the Basic Multilingual Plane.  Solid
+// rendering is quick, although not as smooth as the other rendering types.
+func (font *Font) RenderGlyph_Solid(ch rune, color sdl.Color) *sdl.Surface {
+	cch, ok := glyph(ch)
+	if !ok {
+		return nil
+	}
+	ccol := C.SDL_Color{C.Uint8(color.R), C.Uint8(color.G), C.Uint8(color.B), C.Uint8(color.A)}
+	return wrap(C.TTF_RenderGlyph_Solid(font.cfont, cch, ccol))
+}
+
+// Renders a single glyph in the specified color (and with the specified
+// background color) and returns an SDL surface, or nil if the rune is
+// outside the Basic Multilingual Plane.
+func (font *Font) RenderGlyph_Shaded(ch rune, color, bgcolor sdl.Color) *sdl.Surface {
+	cch, ok := glyph(ch)
+	if !ok {
+		return nil
+	}
+	ccol := C.SDL_Color{C.Uint8(color.R), C.Uint8(color.G), C.Uint8(color.B), C.Uint8(color.A)}
+	cbgcol := C.SDL_Color{C.Uint8(bgcolor.R), C.Uint8(bgcolor.G), C.Uint8(bgcolor.B), C.Uint8(bgcolor.A)}
+	return wrap(C.TTF_RenderGlyph_Shaded(font.cfont, cch, ccol, cbgcol))
+}
+
+// Renders a single glyph in the specified color and returns an SDL surface
+// with an alpha channel, or nil if the rune is outside the Basic
+// Multilingual Plane.  This is the best choice for building glyph atlases.
+func (font *Font) RenderGlyph_Blended(ch rune, color sdl.Color) *sdl.Surface {
+	cch, ok := glyph(ch)
+	if !ok {
+		return nil
+	}
+	ccol := C.SDL_Color{C.Uint8(color.R), C.Uint8(color.G), C.Uint8(color.B), C.Uint8(color.A)}
+	return wrap(C.TTF_RenderGlyph_Blended(font.cfont, cch, ccol))
+}
+
+// Returns the index of the glyph for a rune in the font, or 0 if the
+// font does not provide it.
+func (f *Font) GlyphIsProvided(ch rune) int {
+	cch, ok := glyph(ch)
+	if !ok {
+		return 0
+	}
+	return int(C.TTF_GlyphIsProvided(f.cfont, cch))
+}
+
+// Returns 1 if kerning is enabled for the font and 0 if not.
+func (f *Font) GetKerning() int {
+	result := int(C.TTF_GetFontKerning(f.cfont))
+	return result
+}
+
+// Enables (1) or disables (0) kerning for the font.  Kerning is enabled
+// by default.
+func (f *Font) SetKerning(allowed int) {
+	C.TTF_SetFontKerning(f.cfont, C.int(allowed))
+}
+
+// Returns the kerning offset, in pixels, to apply between two adjacent
+// runes.
+func (f *Font) KerningSize(prev, ch rune) (int, error) {
+	cprev, ok1 := glyph(prev)
+	cch, ok2 := glyph(ch)
+	if !ok1 || !ok2 {
+		return 0, ErrRune
+	}
+	return int(C.TTF_GetFontKerningSizeGlyphs(f.cfont, cprev, cch)), nil
+}
+
+// Returns the outline width of the font in pixels.
+func (f *Font) GetOutline() int {
+	result := int(C.TTF_GetFontOutline(f.cfont))
+	return result
+}
+
+// Sets the outline width of the font in pixels.  Rendering with an
+// outline renders only the outline, so draw text twice for outlined
+// text.  Passing 0 disables outlining.
+func (f *Font) SetOutline(outline int) {
+	C.TTF_SetFontOutline(f.cfont, C.int(outline))
+}
+
 // Set and retrieve FreeType hinter settings
 func (f *Font) GetHinting() int {
 	result := int(C.TTF_GetFontHinting(f.cfont))
@@ -266,13 +429,17 @@ func (f *Font) StyleName() string {
 //
 // For more information on glyph metrics, visit
 // http://freetype.sourceforge.net/freetype2/docs/tutorial/step2.html
-func (f *Font) GlyphMetrics(ch uint16) (int, int, int, int, int, error) {
+func (f *Font) GlyphMetrics(ch rune) (int, int, int, int, int, error) {
+	cch, ok := glyph(ch)
+	if !ok {
+		return 0, 0, 0, 0, 0, ErrRune
+	}
 	minx := C.int(0)
 	maxx := C.int(0)
 	miny := C.int(0)
 	maxy := C.int(0)
 	advance := C.int(0)
-	err := C.TTF_GlyphMetrics(f.cfont, C.Uint16(ch), &minx, &maxx, &miny, &maxy, &advance)
+	err := C.TTF_GlyphMetrics(f.cfont, cch, &minx, &maxx, &miny, &maxy, &advance)
 	if int(err) != 0 {
 		return int(minx), int(maxx), int(miny), int(maxy), int(advance), sdl.NewSDLError()
 	}